@@ -0,0 +1,64 @@
+package hashira
+
+import (
+	"math/big"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// BatchResult is the outcome of reconstructing one share file passed to
+// RecoverSecretBatch.
+type BatchResult struct {
+	Secret *big.Int
+	Err    error
+}
+
+// RecoverSecretBatch parses each of inputs as a share file with ParseInput
+// and recovers its secret from the k points with the smallest x-coordinates,
+// as the hashira command does, so that a file with more than k inconsistent
+// shares gives the same secret either way. The files are processed in parallel; the
+// results are in the same order as inputs. It is safe to call concurrently.
+func RecoverSecretBatch(inputs [][]byte) []BatchResult {
+	results := make([]BatchResult, len(inputs))
+
+	workers := runtime.NumCPU()
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each index is handled by exactly one worker, so results needs
+			// no locking.
+			for i := range jobs {
+				results[i] = recoverOne(inputs[i])
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// recoverOne parses and reconstructs a single share file for
+// RecoverSecretBatch.
+func recoverOne(data []byte) BatchResult {
+	points, k, err := ParseInput(data)
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].X.Cmp(points[j].X) < 0
+	})
+	secret, _, err := RecoverSecretDetailed(points, k)
+	return BatchResult{Secret: secret, Err: err}
+}
@@ -0,0 +1,54 @@
+package hashira
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecoverSecretBatchMatchesSequential(t *testing.T) {
+	inputs := make([][]byte, 100)
+	for i := range inputs {
+		switch i % 4 {
+		case 0:
+			// A malformed value.
+			inputs[i] = []byte(`{"keys":{"n":1,"k":1},"1":{"base":"10","value":"x"}}`)
+		case 1:
+			// Points on a line with slope 1/2, so the secret is not an integer.
+			inputs[i] = []byte(fmt.Sprintf(`{"keys":{"n":2,"k":2},"1":{"base":"10","value":"%d"},"3":{"base":"10","value":"%d"}}`, i, i+1))
+		default:
+			// f(x) = x^2 + i, written in base 16.
+			inputs[i] = []byte(fmt.Sprintf(`{"n":3,"k":3,"shares":[{"x":"1","base":"16","value":"%x"},{"x":"2","base":"16","value":"%x"},{"x":"3","base":"16","value":"%x"}]}`, 1+i, 4+i, 9+i))
+		}
+	}
+
+	got := RecoverSecretBatch(inputs)
+	if len(got) != len(inputs) {
+		t.Fatalf("got %d results, want %d", len(got), len(inputs))
+	}
+	for i, data := range inputs {
+		want := recoverOne(data)
+		switch {
+		case (got[i].Err == nil) != (want.Err == nil):
+			t.Errorf("input %d: err = %v, want %v", i, got[i].Err, want.Err)
+		case got[i].Err != nil:
+			if got[i].Err.Error() != want.Err.Error() {
+				t.Errorf("input %d: err = %v, want %v", i, got[i].Err, want.Err)
+			}
+		case got[i].Secret.Cmp(want.Secret) != 0:
+			t.Errorf("input %d: secret = %s, want %s", i, got[i].Secret, want.Secret)
+		case got[i].Secret.Int64() != int64(i):
+			t.Errorf("input %d: secret = %s, want %d", i, got[i].Secret, i)
+		}
+	}
+}
+
+func TestRecoverSecretBatchSortsByX(t *testing.T) {
+	// The shares at x=1, 2 lie on f(x) = x + 3 and the one at x=7 does not,
+	// so the secret depends on which two are used. It comes first in the
+	// file, but the two smallest x-coordinates are used.
+	input := []byte(`{"keys":{"n":3,"k":2},"7":{"base":"10","value":"100"},"2":{"base":"10","value":"5"},"1":{"base":"10","value":"4"}}`)
+	got := RecoverSecretBatch([][]byte{input})
+	if got[0].Err != nil || got[0].Secret.Int64() != 3 {
+		t.Errorf("RecoverSecretBatch = %v, %v; want 3", got[0].Secret, got[0].Err)
+	}
+}
@@ -0,0 +1,219 @@
+package hashira
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RecoverWithErrorCorrection recovers the secret over GF(prime) from shares
+// of which up to (len(points)-k)/2 may be corrupt, using the Berlekamp-Welch
+// algorithm. With e = (n-k)/2 it solves the linear system
+//
+//	Q(x_i) = y_i * E(x_i)  for every share i
+//
+// for a monic error locator E of degree e and a polynomial Q of degree below
+// k+e, then divides to obtain the message polynomial P = Q/E of degree below
+// k whose constant term is the secret. It returns an error if the shares have
+// more errors than can be corrected.
+func RecoverWithErrorCorrection(points []Point, k int, prime *big.Int) (*big.Int, error) {
+	if err := validateErrorCorrection(points, k, prime); err != nil {
+		return nil, err
+	}
+	return berlekampWelch(points, k, (len(points)-k)/2, prime)
+}
+
+// RecoverWithErrorCorrectionMax is like RecoverWithErrorCorrection but
+// corrects at most maxErrors corrupt shares, which must be between 0 and
+// (len(points)-k)/2. The linear system shrinks with the error budget, and if
+// more than maxErrors shares are corrupt it reports that rather than
+// returning a secret.
+func RecoverWithErrorCorrectionMax(points []Point, k, maxErrors int, prime *big.Int) (*big.Int, error) {
+	if err := validateErrorCorrection(points, k, prime); err != nil {
+		return nil, err
+	}
+	if err := validateMaxErrors(maxErrors, k, len(points)); err != nil {
+		return nil, err
+	}
+	return berlekampWelch(points, k, maxErrors, prime)
+}
+
+// validateErrorCorrection checks the arguments shared by the error-correcting
+// recovery functions.
+func validateErrorCorrection(points []Point, k int, prime *big.Int) error {
+	if err := ValidatePrime(prime); err != nil {
+		return err
+	}
+	if err := validatePoints(points); err != nil {
+		return err
+	}
+	return validateThreshold(k, len(points))
+}
+
+// validateMaxErrors checks that maxErrors corrupt shares out of n can be
+// corrected with threshold k: any two polynomials that each agree with all but
+// maxErrors shares share k points, and so are the same, only when
+// 2*maxErrors <= n-k.
+func validateMaxErrors(maxErrors, k, n int) error {
+	if maxErrors < 0 || 2*maxErrors > n-k {
+		return fmt.Errorf("max errors %d out of range: %d shares with k=%d can correct between 0 and %d", maxErrors, n, k, (n-k)/2)
+	}
+	return nil
+}
+
+// berlekampWelch runs the Berlekamp-Welch decoder on validated arguments,
+// correcting up to e errors.
+func berlekampWelch(points []Point, k, e int, prime *big.Int) (*big.Int, error) {
+	n := len(points)
+
+	xs := make([]*big.Int, n)
+	ys := make([]*big.Int, n)
+	seen := make(map[string]bool, n)
+	for i, p := range points {
+		xs[i] = new(big.Int).Mod(p.X, prime)
+		ys[i] = new(big.Int).Mod(p.Y, prime)
+		if seen[xs[i].String()] {
+			return nil, fmt.Errorf("x-coordinate %s is not distinct modulo %s", p.X, prime)
+		}
+		seen[xs[i].String()] = true
+	}
+
+	cols := k + 2*e
+
+	// Unknowns are E_0..E_{e-1} followed by Q_0..Q_{k+e-1}; the leading
+	// coefficient of E is fixed at 1 and moved to the right-hand side.
+	rows := make([][]*big.Int, n)
+	for i := 0; i < n; i++ {
+		// pows[j] = x_i^j for j = 0..k+e.
+		pows := make([]*big.Int, k+e+1)
+		pows[0] = big.NewInt(1)
+		for j := 1; j < len(pows); j++ {
+			pows[j] = new(big.Int).Mul(pows[j-1], xs[i])
+			pows[j].Mod(pows[j], prime)
+		}
+
+		row := make([]*big.Int, cols+1)
+		for j := 0; j < e; j++ {
+			row[j] = new(big.Int).Mul(ys[i], pows[j])
+			row[j].Neg(row[j]).Mod(row[j], prime)
+		}
+		for j := 0; j < k+e; j++ {
+			row[e+j] = new(big.Int).Set(pows[j])
+		}
+		row[cols] = new(big.Int).Mul(ys[i], pows[e])
+		row[cols].Mod(row[cols], prime)
+		rows[i] = row
+	}
+
+	sol, ok := solveMod(rows, cols, prime)
+	if !ok {
+		return nil, errTooManyErrors(e)
+	}
+
+	locator := append(sol[:e:e], big.NewInt(1))
+	message, remainder := polyDivMod(sol[e:], locator, prime)
+	for _, c := range remainder {
+		if c.Sign() != 0 {
+			return nil, errTooManyErrors(e)
+		}
+	}
+
+	mismatches := 0
+	for i := 0; i < n; i++ {
+		if evalPolyMod(message, xs[i], prime).Cmp(ys[i]) != 0 {
+			mismatches++
+		}
+	}
+	if mismatches > e {
+		return nil, errTooManyErrors(e)
+	}
+
+	if len(message) == 0 {
+		return new(big.Int), nil
+	}
+	return message[0], nil
+}
+
+func errTooManyErrors(e int) error {
+	return fmt.Errorf("cannot correct errors: more than %d corrupted shares", e)
+}
+
+// solveMod solves the linear system given by the augmented matrix rows,
+// which has cols unknowns, over GF(prime) by Gauss-Jordan elimination. Free
+// variables are set to zero. It reports false if the system is inconsistent.
+// rows is modified in place.
+func solveMod(rows [][]*big.Int, cols int, prime *big.Int) ([]*big.Int, bool) {
+	pivotCols := make([]int, 0, cols)
+	r := 0
+	for c := 0; c < cols && r < len(rows); c++ {
+		pivot := -1
+		for i := r; i < len(rows); i++ {
+			if rows[i][c].Sign() != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		rows[r], rows[pivot] = rows[pivot], rows[r]
+
+		inv := new(big.Int).ModInverse(rows[r][c], prime)
+		for j := c; j <= cols; j++ {
+			rows[r][j].Mul(rows[r][j], inv).Mod(rows[r][j], prime)
+		}
+		for i := range rows {
+			if i == r || rows[i][c].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Int).Set(rows[i][c])
+			for j := c; j <= cols; j++ {
+				t := new(big.Int).Mul(factor, rows[r][j])
+				rows[i][j].Sub(rows[i][j], t).Mod(rows[i][j], prime)
+			}
+		}
+		pivotCols = append(pivotCols, c)
+		r++
+	}
+
+	for i := r; i < len(rows); i++ {
+		if rows[i][cols].Sign() != 0 {
+			return nil, false
+		}
+	}
+
+	sol := make([]*big.Int, cols)
+	for c := range sol {
+		sol[c] = new(big.Int)
+	}
+	for i, c := range pivotCols {
+		sol[c].Set(rows[i][cols])
+	}
+	return sol, true
+}
+
+// polyDivMod divides num by the monic polynomial den over GF(prime).
+// Coefficients are ordered from the constant term up.
+func polyDivMod(num, den []*big.Int, prime *big.Int) (quotient, remainder []*big.Int) {
+	rem := make([]*big.Int, len(num))
+	for i, c := range num {
+		rem[i] = new(big.Int).Set(c)
+	}
+	d := len(den) - 1
+	if len(rem) <= d {
+		return nil, rem
+	}
+
+	quotient = make([]*big.Int, len(rem)-d)
+	for i := len(rem) - 1; i >= d; i-- {
+		q := new(big.Int).Set(rem[i])
+		quotient[i-d] = q
+		if q.Sign() == 0 {
+			continue
+		}
+		for j := 0; j <= d; j++ {
+			t := new(big.Int).Mul(q, den[j])
+			rem[i-d+j].Sub(rem[i-d+j], t).Mod(rem[i-d+j], prime)
+		}
+	}
+	return quotient, rem[:d]
+}
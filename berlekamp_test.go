@@ -0,0 +1,108 @@
+package hashira
+
+import (
+	"math/big"
+	"testing"
+)
+
+// fieldShares returns the shares at x = 1..n of f(x) = 3x^2 + 5x + 42 over
+// GF(101), with 1 added to the y-coordinates at the indices in corrupt.
+func fieldShares(n int, corrupt ...int) []Point {
+	prime := big.NewInt(101)
+	points := make([]Point, n)
+	for i := range points {
+		x := int64(i + 1)
+		y := big.NewInt(3*x*x + 5*x + 42)
+		points[i] = Point{X: big.NewInt(x), Y: y.Mod(y, prime)}
+	}
+	for _, i := range corrupt {
+		points[i].Y.Add(points[i].Y, big.NewInt(1)).Mod(points[i].Y, prime)
+	}
+	return points
+}
+
+func TestRecoverWithErrorCorrection(t *testing.T) {
+	prime := big.NewInt(101)
+	tests := []struct {
+		name    string
+		points  []Point
+		wantErr bool
+	}{
+		{"no errors", fieldShares(7), false},
+		{"first share", fieldShares(7, 0), false},
+		{"middle share", fieldShares(7, 3), false},
+		{"two shares", fieldShares(7, 1, 5), false},
+		{"both ends", fieldShares(7, 0, 6), false},
+		{"no spare shares", fieldShares(3), false},
+		{"three of seven", fieldShares(7, 0, 2, 4), true},
+		{"two of six", fieldShares(6, 1, 4), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := RecoverWithErrorCorrection(tt.points, 3, prime)
+			switch {
+			case tt.wantErr && err == nil:
+				t.Errorf("got secret %s, want an error", secret)
+			case !tt.wantErr && err != nil:
+				t.Errorf("got error %v, want 42", err)
+			case !tt.wantErr && secret.Int64() != 42:
+				t.Errorf("got secret %s, want 42", secret)
+			}
+		})
+	}
+}
+
+func TestRecoverWithErrorCorrectionMax(t *testing.T) {
+	prime := big.NewInt(101)
+	tests := []struct {
+		name      string
+		points    []Point
+		maxErrors int
+		wantErr   bool
+	}{
+		{"no errors, no budget", fieldShares(7), 0, false},
+		{"one error, budget 1", fieldShares(7, 2), 1, false},
+		{"two errors, budget 2", fieldShares(7, 2, 3), 2, false},
+		{"one error, no budget", fieldShares(7, 2), 0, true},
+		{"two errors, budget 1", fieldShares(7, 2, 3), 1, true},
+		{"negative budget", fieldShares(7), -1, true},
+		{"budget above (n-k)/2", fieldShares(7), 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := RecoverWithErrorCorrectionMax(tt.points, 3, tt.maxErrors, prime)
+			switch {
+			case tt.wantErr && err == nil:
+				t.Errorf("got secret %s, want an error", secret)
+			case !tt.wantErr && (err != nil || secret.Int64() != 42):
+				t.Errorf("got %v, %v; want 42", secret, err)
+			}
+		})
+	}
+}
+
+func TestRecoverWithErrorCorrectionInputs(t *testing.T) {
+	prime := big.NewInt(101)
+
+	// x=102 is x=1 modulo 101.
+	colliding := append(fieldShares(4), Point{X: big.NewInt(102), Y: big.NewInt(50)})
+	if _, err := RecoverWithErrorCorrection(colliding, 3, prime); err == nil {
+		t.Error("x-coordinates colliding modulo the prime: got nil error")
+	}
+
+	// y-coordinates at or above the prime are reduced before decoding.
+	unreduced := fieldShares(7, 4)
+	for _, p := range unreduced {
+		p.Y.Add(p.Y, prime)
+	}
+	if secret, err := RecoverWithErrorCorrection(unreduced, 3, prime); err != nil || secret.Int64() != 42 {
+		t.Errorf("y-coordinates above the prime: got %v, %v; want 42", secret, err)
+	}
+
+	if _, err := RecoverWithErrorCorrection(fieldShares(7), 3, big.NewInt(100)); err == nil {
+		t.Error("composite modulus: got nil error")
+	}
+	if _, err := RecoverWithErrorCorrection(fieldShares(2), 3, prime); err == nil {
+		t.Error("fewer shares than k: got nil error")
+	}
+}
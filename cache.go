@@ -0,0 +1,98 @@
+package hashira
+
+import (
+	"math/big"
+	"strconv"
+	"sync"
+)
+
+// maxCacheEntries bounds the number of values a secretCache holds. Once it is
+// full, values not already stored are computed directly rather than by the
+// recurrence, which without stored sub-results would cost about 2^k.
+const maxCacheEntries = 1 << 16
+
+// maxCachedK is the largest combination size built with the recurrence.
+// Larger combinations share few sub-combinations and would fill the cache
+// with big fractions, so they are interpolated directly.
+const maxCachedK = 16
+
+// secretCache memoizes f_S(0) for subsets S of one slice of points, where f_S
+// is the polynomial through the points in S. A subset is keyed by the indices
+// of its points in ascending order, which within one slice identifies its
+// set of x-coordinates.
+//
+// Values are built with Neville's recurrence
+//
+//	f_S(0) = (x_b f_{S\b}(0) - x_a f_{S\a}(0)) / (x_b - x_a)
+//
+// from the two subsets that drop the first point a or the last point b of S.
+// The combinations tried by consensus share most of these smaller subsets,
+// so each new combination costs about k operations instead of the k^2 of a
+// full Lagrange interpolation. A cache is used for a single call and is safe
+// for concurrent use.
+type secretCache struct {
+	points []Point
+
+	mu     sync.Mutex
+	values map[string]*big.Rat
+}
+
+func newSecretCache(points []Point) *secretCache {
+	return &secretCache{points: points, values: make(map[string]*big.Rat)}
+}
+
+// secret returns f_S(0) for the points at the indices in combo, which must be
+// ascending and nonempty. The result must not be modified.
+func (c *secretCache) secret(combo []int) *big.Rat {
+	if len(combo) == 1 {
+		return new(big.Rat).SetInt(c.points[combo[0]].Y)
+	}
+
+	if len(combo) > maxCachedK {
+		return c.direct(combo)
+	}
+
+	key := comboKey(combo)
+	c.mu.Lock()
+	v, ok := c.values[key]
+	full := len(c.values) >= maxCacheEntries
+	c.mu.Unlock()
+	if ok {
+		return v
+	}
+	if full {
+		return c.direct(combo)
+	}
+
+	a, b := c.points[combo[0]].X, c.points[combo[len(combo)-1]].X
+	withoutA := c.secret(combo[1:])
+	withoutB := c.secret(combo[:len(combo)-1])
+
+	v = new(big.Rat).Mul(new(big.Rat).SetInt(b), withoutB)
+	v.Sub(v, new(big.Rat).Mul(new(big.Rat).SetInt(a), withoutA))
+	v.Quo(v, new(big.Rat).SetInt(new(big.Int).Sub(b, a)))
+
+	c.mu.Lock()
+	if len(c.values) < maxCacheEntries {
+		c.values[key] = v
+	}
+	c.mu.Unlock()
+	return v
+}
+
+// direct returns f_S(0) for the points at the indices in combo by Lagrange
+// interpolation, without the cache.
+func (c *secretCache) direct(combo []int) *big.Rat {
+	num, den := lagrangeFracAtZero(subsetOf(c.points, combo))
+	return new(big.Rat).SetFrac(num, den)
+}
+
+// comboKey encodes a combination of indices as a map key.
+func comboKey(combo []int) string {
+	buf := make([]byte, 0, 4*len(combo))
+	for _, idx := range combo {
+		buf = strconv.AppendInt(buf, int64(idx), 36)
+		buf = append(buf, ',')
+	}
+	return string(buf)
+}
@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+// Command hashira-wasm exposes secret reconstruction to JavaScript when
+// compiled to WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o hashira.wasm ./cmd/hashira-wasm
+//
+// Once loaded with the wasm_exec.js shipped with Go, it defines a global
+// function
+//
+//	hashiraRecover(shareFileJSON) -> {secret: "..."} or {error: "..."}
+//
+// that decodes a share file and recovers its secret from the first k points
+// in x order, as the hashira command does by default.
+package main
+
+import (
+	"sort"
+	"syscall/js"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+func main() {
+	js.Global().Set("hashiraRecover", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return map[string]any{"error": "hashiraRecover takes one argument, the share file JSON as a string"}
+		}
+		secret, err := recoverSecret(args[0].String())
+		if err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+		return map[string]any{"secret": secret}
+	}))
+
+	// Keep the Go runtime alive so that the function stays callable.
+	select {}
+}
+
+// recoverSecret decodes the share file data and returns its secret in
+// decimal.
+func recoverSecret(data string) (string, error) {
+	points, k, err := hashira.ParseInput([]byte(data))
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].X.Cmp(points[j].X) < 0
+	})
+	if err := hashira.ValidateShareSet(points, k); err != nil {
+		return "", err
+	}
+	secret, _, err := hashira.RecoverSecretDetailed(points, k)
+	if err != nil {
+		return "", err
+	}
+	return secret.String(), nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+// checkReader validates the share file read from r and prints whether it is
+// well-formed. With consistent set, it also checks that every point lies on
+// the polynomial through the first k in x order and, if -secret was given,
+// that its constant term is that secret; otherwise nothing is interpolated.
+// name labels the output and is empty when only one input is being
+// processed. It returns an error if any problem was found.
+func checkReader(r io.Reader, name string, opts options, consistent bool) error {
+	label := name
+	if label == "" {
+		label = "input"
+	}
+
+	dec := hashira.NewDecoder(r)
+	dec.MaxPoints = opts.maxPoints
+	dec.Decimals = opts.decimal
+	dec.ValuePath = opts.valuePath
+	dec.DefaultBase = opts.valueBase
+	in, err := dec.Decode()
+	if err != nil {
+		return err
+	}
+	logWarnings(in)
+
+	problems := checkInput(in, opts)
+	if len(problems) == 0 && consistent {
+		problems = checkConsistent(in, opts)
+	}
+	if len(problems) == 0 {
+		fmt.Fprintf(opts.out, "%s: OK (%d points, k=%d)\n", label, len(in.Points), effectiveK(in.K, opts))
+		return nil
+	}
+
+	noun := "problems"
+	if len(problems) == 1 {
+		noun = "problem"
+	}
+	fmt.Fprintf(opts.out, "%s: %d %s\n", label, len(problems), noun)
+	for _, p := range problems {
+		fmt.Fprintf(opts.out, "  - %s\n", p)
+	}
+	return errors.New("not well-formed")
+}
+
+// checkInput returns a description of each problem that would stop the
+// secret from being recovered from in.
+func checkInput(in *hashira.Input, opts options) []string {
+	var problems []string
+
+	seen := make(map[string]int, len(in.Points))
+	for _, p := range in.Points {
+		seen[p.X.String()]++
+	}
+	var dups []string
+	for _, p := range in.Points {
+		key := p.X.String()
+		if seen[key] > 1 {
+			dups = append(dups, key)
+			seen[key] = 0
+		}
+	}
+	if len(dups) > 0 {
+		problems = append(problems, "duplicate x-coordinates: "+strings.Join(dups, ", "))
+	}
+
+	k := effectiveK(in.K, opts)
+	switch {
+	case k < 1:
+		problems = append(problems, fmt.Sprintf("k=%d: must be at least 1", k))
+	case len(in.Points) < hashira.MinPointsRequired(k):
+		problems = append(problems, fmt.Sprintf("not enough points (%d) to meet requirement k=%d", len(in.Points), k))
+	}
+
+	if in.N != 0 && in.N != len(in.Points) {
+		problems = append(problems, fmt.Sprintf("keys.n is %d but %d points were decoded", in.N, len(in.Points)))
+	}
+
+	return problems
+}
+
+// checkConsistent returns a description of each way in which the points of
+// the well-formed input in disagree with each other or with -secret.
+func checkConsistent(in *hashira.Input, opts options) []string {
+	points := append([]hashira.Point(nil), in.Points...)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].X.Cmp(points[j].X) < 0
+	})
+	k := effectiveK(in.K, opts)
+
+	secret, err := hashira.RecoverSecret(points[:k])
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if opts.secret != nil && secret.Cmp(opts.secret) != 0 {
+		problems = append(problems, fmt.Sprintf("the first %d points do not recover the expected -secret", k))
+	}
+	_, err = hashira.VerifySecret(points, k, secret)
+	var mismatch *hashira.MismatchError
+	switch {
+	case errors.As(err, &mismatch):
+		problems = append(problems, mismatch.Error())
+	case err != nil:
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// effectiveK returns the threshold to use: the -k flag if set, otherwise k
+// from the input.
+func effectiveK(k int, opts options) int {
+	if opts.k != 0 {
+		return opts.k
+	}
+	return k
+}
+
+const verifyUsage = `usage: hashira verify [flags] [path_to_json_file | -]...
+
+Checks that each share file is well-formed, as "hashira -check" does, and
+that all of its points lie on one polynomial, without printing the secret.
+Exits non-zero if any file has a problem.
+
+Flags:`
+
+// runVerify runs the verify subcommand.
+func runVerify(args []string) error {
+	var opts options
+	fs := flag.NewFlagSet("hashira verify", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), verifyUsage)
+		fs.PrintDefaults()
+	}
+	fs.IntVar(&opts.k, "k", 0, "threshold to check against, 0 meaning keys.k from the file")
+	secretFlag(fs, &opts, "base-10 secret the shares are expected to recover")
+	fs.StringVar(&opts.input, "input", "", "share file JSON given inline instead of as a file or on stdin")
+	fs.IntVar(&opts.maxPoints, "max-points", 10000, "reject inputs with more than this many points (0 means unlimited)")
+	formatFlag(fs, &opts)
+	valueFlags(fs, &opts)
+	outputFlags(fs, &opts)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(false))
+
+	if err := checkFormat(opts.format); err != nil {
+		return err
+	}
+	paths, err := inputPaths(fs, opts)
+	if err != nil {
+		return err
+	}
+	return withOutput(&opts, func() error {
+		return forEachInput(paths, opts, func(name string, r io.Reader) error {
+			return checkReader(r, name, opts, true)
+		})
+	})
+}
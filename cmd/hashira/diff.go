@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+const diffUsage = `usage: hashira diff [flags] a.json b.json
+
+Recovers the secret from both share files and reports whether it is the
+same, for checking that re-encoding or re-basing shares preserved it. If
+the secrets differ both are shown and the exit status is 1. Files holding an
+array or a sequence of share files are compared case by case, and must hold
+the same number of cases.
+
+Flags:`
+
+// runDiff runs the diff subcommand.
+func runDiff(args []string) error {
+	var opts options
+	fs := flag.NewFlagSet("hashira diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), diffUsage)
+		fs.PrintDefaults()
+	}
+	fs.IntVar(&opts.k, "k", 0, "number of points to interpolate, 0 meaning keys.k from each file")
+	fs.BoolVar(&opts.consensus, "consensus", false, "recover the secret most combinations of k points agree on, tolerating corrupt shares")
+	fs.BoolVar(&opts.noVerify, "no-verify", false, "skip checking that the points beyond the k used lie on the recovered polynomial")
+	fs.IntVar(&opts.base, "base", 10, "base (2-36) in which to print the secrets")
+	fs.IntVar(&opts.maxPoints, "max-points", 10000, "reject inputs with more than this many points (0 means unlimited)")
+	formatFlag(fs, &opts)
+	valueFlags(fs, &opts)
+	outputFlags(fs, &opts)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(false))
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("diff takes exactly two files")
+	}
+	if opts.base < 2 || opts.base > 36 {
+		return fmt.Errorf("-base %d out of range: must be between 2 and 36", opts.base)
+	}
+	if err := checkFormat(opts.format); err != nil {
+		return err
+	}
+
+	// cases[i] holds the secret of each case in the i-th file: one for a
+	// single share file, or one per element of an array or per document.
+	var cases [2][]diffCase
+	for i, path := range fs.Args() {
+		err := forEachInput([]string{path}, opts, func(name string, r io.Reader) error {
+			res, err := recoverReader(r, opts)
+			if err != nil {
+				return err
+			}
+			cases[i] = append(cases[i], diffCase{name: name, secret: secretText(res, opts.base)})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	a, b := fs.Arg(0), fs.Arg(1)
+	if len(cases[0]) != len(cases[1]) {
+		return fmt.Errorf("%s holds %d cases but %s holds %d", a, len(cases[0]), b, len(cases[1]))
+	}
+	return withOutput(&opts, func() error {
+		differ := 0
+		for j := range cases[0] {
+			ca, cb := cases[0][j], cases[1][j]
+			prefix := ""
+			if ca.name != "" {
+				prefix = "case " + ca.name + ": "
+			}
+			if ca.secret == cb.secret {
+				fmt.Fprintf(opts.out, "%ssame secret: %s\n", prefix, ca.secret)
+				continue
+			}
+			differ++
+			fmt.Fprintf(opts.out, "%ssecrets differ:\n", prefix)
+			fmt.Fprintf(opts.out, "  %s%s: %s\n", a, ca.name, ca.secret)
+			fmt.Fprintf(opts.out, "  %s%s: %s\n", b, cb.name, cb.secret)
+		}
+		switch {
+		case differ == 0:
+			return nil
+		case len(cases[0]) == 1:
+			return errors.New("secrets differ")
+		default:
+			return fmt.Errorf("secrets differ in %d of %d cases", differ, len(cases[0]))
+		}
+	})
+}
+
+// diffCase is the secret recovered from one case of a file given to diff.
+type diffCase struct {
+	name   string // the case's label, such as "[1]", or "" for a single share file
+	secret string
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+// Exit codes, listed in usage so that scripts can tell failures apart.
+const (
+	exitFailure      = 1 // a usage error or any failure not listed below
+	exitParse        = 2 // a share file could not be parsed
+	exitNonInteger   = 3 // the secret is not an integer
+	exitInsufficient = 4 // fewer points than the threshold k
+)
+
+const exitCodesUsage = `Exit status is 0 on success, 1 for usage errors and failures not listed
+here, 2 if a share file cannot be parsed, 3 if the secret is not an integer
+and 4 if there are fewer points than the threshold k. When several files
+are given and they all fail the same way, that failure's status is used;
+otherwise it is 1.`
+
+// errUsage is returned for a command line that the flag package has already
+// reported along with the usage message.
+var errUsage = errors.New("invalid command line")
+
+// parseFlags parses args with fs, which must use flag.ContinueOnError. It
+// returns flag.ErrHelp if -h was given and errUsage for other errors, which
+// fs has already printed.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return errUsage
+}
+
+// exitError carries the exit code for an error that does not imply one by
+// its type, such as the summary of several failed files.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// exitCode returns the exit status to report for err.
+func exitCode(err error) int {
+	var (
+		ee *exitError
+		de *hashira.DecodeError
+		fe *hashira.FormatError
+		ne *hashira.NonIntegerError
+		ie *hashira.InsufficientPointsError
+	)
+	switch {
+	case errors.As(err, &ee):
+		return ee.code
+	case errors.As(err, &de), errors.As(err, &fe):
+		return exitParse
+	case errors.As(err, &ne):
+		return exitNonInteger
+	case errors.As(err, &ie):
+		return exitInsufficient
+	}
+	return exitFailure
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+// maxExplainK is the largest k for which -explain writes out the arithmetic;
+// beyond it the products no longer fit on a line.
+const maxExplainK = 8
+
+// explain writes the Lagrange computation of f(0) from points to w step by
+// step: each basis value L_i(0) as a product of fractions, its term y_i *
+// L_i(0), and the running sum.
+func explain(w io.Writer, points []hashira.Point) error {
+	if len(points) > maxExplainK {
+		fmt.Fprintf(w, "-explain shows the arithmetic for at most %d points; k=%d\n", maxExplainK, len(points))
+		return nil
+	}
+	basis, err := hashira.LagrangeBasisAtZero(points)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Lagrange interpolation at x=0 from %d points:\n", len(points))
+	fmt.Fprintln(w, "  f(0) = sum of y_i * L_i(0), L_i(0) = product over j != i of (0 - x_j) / (x_i - x_j)")
+	sum := new(big.Rat)
+	for i, p := range points {
+		var num, den []string
+		numProd, denProd := big.NewInt(1), big.NewInt(1)
+		for j, q := range points {
+			if i != j {
+				num = append(num, "(0 - "+parenthesize(q.X)+")")
+				den = append(den, "("+p.X.String()+" - "+parenthesize(q.X)+")")
+				numProd.Mul(numProd, new(big.Int).Neg(q.X))
+				denProd.Mul(denProd, new(big.Int).Sub(p.X, q.X))
+			}
+		}
+		fmt.Fprintf(w, "\n  L_%d(0) = ", i)
+		if len(num) == 0 {
+			fmt.Fprintln(w, "1 (the only point)")
+		} else {
+			denText := strings.Join(den, "")
+			if len(den) > 1 {
+				denText = "(" + denText + ")"
+			}
+			fmt.Fprintf(w, "%s / %s = %s / %s = %s\n", strings.Join(num, ""), denText, numProd, parenthesize(denProd), basis[i].RatString())
+		}
+
+		term := new(big.Rat).Mul(new(big.Rat).SetInt(p.Y), basis[i])
+		sum.Add(sum, term)
+		basisText := basis[i].RatString()
+		if basis[i].Sign() < 0 {
+			basisText = "(" + basisText + ")"
+		}
+		fmt.Fprintf(w, "  y_%d * L_%d(0) = %s * %s = %s\n", i, i, p.Y, basisText, term.RatString())
+		fmt.Fprintf(w, "  running sum = %s\n", sum.RatString())
+	}
+	fmt.Fprintf(w, "\n  f(0) = %s\n", sum.RatString())
+	return nil
+}
+
+// logTerms logs each term y_i * L_i(0) of the Lagrange sum for the secret at
+// debug level, for -v. The library does no logging of its own, so that
+// shares and secrets never reach the logs of programs that use it.
+func logTerms(points []hashira.Point) error {
+	basis, err := hashira.LagrangeBasisAtZero(points)
+	if err != nil {
+		return err
+	}
+	for i, p := range points {
+		term := new(big.Rat).Mul(new(big.Rat).SetInt(p.Y), basis[i])
+		slog.Debug("lagrange term", "i", i, "point", p, "basis", basis[i].RatString(), "term", term.RatString())
+	}
+	return nil
+}
+
+// parenthesize renders x in decimal, wrapped in parentheses if negative.
+func parenthesize(x *big.Int) string {
+	if x.Sign() < 0 {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nefrttPrabhu/hashira"
+	"gopkg.in/yaml.v3"
+)
+
+// A converter turns a share file written in some syntax into the equivalent
+// JSON share file, so that hashira.Decoder serves every format.
+type converter func(data []byte) ([]byte, error)
+
+// converters holds the input formats other than JSON, by -format name.
+var converters = map[string]converter{
+	"yaml": yamlToJSON,
+	"toml": tomlToJSON,
+}
+
+// formatFlag registers -format on fs, storing it in opts.format.
+func formatFlag(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.format, "format", "auto", "input format: json, yaml, toml, or auto to choose by file extension (.yaml, .yml, .toml; JSON otherwise)")
+}
+
+// valueFlags registers -value-path and -value-base on fs, which describe how
+// the shares of the input write their values.
+func valueFlags(fs *flag.FlagSet, opts *options) {
+	fs.Func("value-path", "dot-separated keys under which each share nests its base and value, e.g. data for {\"data\": {\"base\": ..., \"value\": ...}}", func(s string) error {
+		opts.valuePath = strings.Split(s, ".")
+		for _, key := range opts.valuePath {
+			if key == "" {
+				return fmt.Errorf("empty key in %q", s)
+			}
+		}
+		return nil
+	})
+	fs.Func("value-base", fmt.Sprintf("base (2-%d) of the values of shares that have no base field; one that does keeps its own (default 10)", hashira.MaxBase), func(s string) error {
+		base, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		if base < 2 || base > hashira.MaxBase {
+			return fmt.Errorf("must be between 2 and %d", hashira.MaxBase)
+		}
+		opts.valueBase = base
+		return nil
+	})
+}
+
+// checkFormat reports an error if format is not a known -format value.
+func checkFormat(format string) error {
+	if _, ok := converters[format]; ok || format == "json" || format == "auto" {
+		return nil
+	}
+	return fmt.Errorf("-format %q: must be json, yaml, toml or auto", format)
+}
+
+// convertInput returns r as JSON, converting it from format if that is not
+// JSON. JSON input is passed through unread so it can still be streamed.
+func convertInput(r io.Reader, format string) (io.Reader, error) {
+	convert, ok := converters[format]
+	if !ok {
+		return r, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	converted, err := convert(data)
+	if err != nil {
+		return nil, &hashira.FormatError{Err: err}
+	}
+	return bytes.NewReader(converted), nil
+}
+
+// inputFormat returns the format of the input at path: the -format flag if
+// it is not "auto", otherwise a guess from the file extension, ignoring a
+// trailing .gz. Anything unrecognised, including stdin, is JSON.
+func inputFormat(path string, opts options) string {
+	if opts.format != "auto" {
+		return opts.format
+	}
+	switch filepath.Ext(strings.TrimSuffix(path, ".gz")) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return "json"
+}
+
+// A value is one node of a share file after parsing: an *object, a []value
+// array, or a scalar.
+type value any
+
+// object is a mapping whose keys keep the order they had in the file.
+type object struct {
+	keys   []string
+	values []value
+}
+
+// scalar is a leaf value. text is its source form; number marks a number,
+// boolean or null, which is written unquoted where the share file schema
+// expects one.
+type scalar struct {
+	text   string
+	number bool
+}
+
+// yamlToJSON converts a YAML share file. Scalars are taken from their source
+// text, so integers of any size survive.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("parsing YAML: empty document")
+	}
+	return writeShareFile(fromYAML(doc.Content[0]))
+}
+
+func fromYAML(n *yaml.Node) value {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return fromYAML(n.Alias)
+	case yaml.MappingNode:
+		obj := &object{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			obj.keys = append(obj.keys, n.Content[i].Value)
+			obj.values = append(obj.values, fromYAML(n.Content[i+1]))
+		}
+		return obj
+	case yaml.SequenceNode:
+		arr := make([]value, len(n.Content))
+		for i, c := range n.Content {
+			arr[i] = fromYAML(c)
+		}
+		return arr
+	}
+	switch n.Tag {
+	case "!!int", "!!float", "!!bool", "!!null":
+		return scalar{text: n.Value, number: true}
+	}
+	return scalar{text: n.Value}
+}
+
+// tomlToJSON converts a TOML share file. The top-level keys and those of
+// each top-level table keep their file order; deeper keys are sorted.
+func tomlToJSON(data []byte) ([]byte, error) {
+	var m map[string]any
+	md, err := toml.Decode(string(data), &m)
+	if err != nil {
+		return nil, fmt.Errorf("parsing TOML: %w", err)
+	}
+
+	// order maps "" to the top-level keys and each top-level key to the keys
+	// of its table, in file order.
+	order := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, key := range md.Keys() {
+		if len(key) > 2 || seen[key.String()] {
+			continue
+		}
+		seen[key.String()] = true
+		parent := strings.Join(key[:len(key)-1], ".")
+		order[parent] = append(order[parent], key[len(key)-1])
+	}
+
+	root := &object{}
+	for _, k := range order[""] {
+		root.keys = append(root.keys, k)
+		root.values = append(root.values, fromTOML(m[k], order[k]))
+	}
+	return writeShareFile(root)
+}
+
+// fromTOML converts a decoded TOML value. If v is a table, keys gives the
+// order of its entries; if keys is nil they are sorted.
+func fromTOML(v any, keys []string) value {
+	switch v := v.(type) {
+	case map[string]any:
+		if keys == nil {
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+		}
+		obj := &object{}
+		for _, k := range keys {
+			obj.keys = append(obj.keys, k)
+			obj.values = append(obj.values, fromTOML(v[k], nil))
+		}
+		return obj
+	case []map[string]any:
+		arr := make([]value, len(v))
+		for i, item := range v {
+			arr[i] = fromTOML(item, nil)
+		}
+		return arr
+	case []any:
+		arr := make([]value, len(v))
+		for i, item := range v {
+			arr[i] = fromTOML(item, nil)
+		}
+		return arr
+	case string:
+		return scalar{text: v}
+	case int64:
+		return scalar{text: strconv.FormatInt(v, 10), number: true}
+	case float64:
+		return scalar{text: strconv.FormatFloat(v, 'g', -1, 64), number: true}
+	case bool:
+		return scalar{text: strconv.FormatBool(v), number: true}
+	}
+	return scalar{text: fmt.Sprint(v)}
+}
+
+// writeShareFile writes root as JSON. Scalars are quoted strings, as the
+// share file schema expects for x-coordinates, bases and values, except
+// under the "keys", "n" and "k" entries, which hold numbers.
+func writeShareFile(root value) ([]byte, error) {
+	obj, ok := root.(*object)
+	if !ok {
+		return nil, fmt.Errorf("share file must be a mapping at the top level")
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range obj.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeString(&b, k)
+		b.WriteByte(':')
+		writeValue(&b, obj.values[i], k == "keys" || k == "n" || k == "k")
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func writeValue(b *bytes.Buffer, v value, numbers bool) {
+	switch v := v.(type) {
+	case *object:
+		b.WriteByte('{')
+		for i, k := range v.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeString(b, k)
+			b.WriteByte(':')
+			writeValue(b, v.values[i], numbers)
+		}
+		b.WriteByte('}')
+	case []value:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeValue(b, item, numbers)
+		}
+		b.WriteByte(']')
+	case scalar:
+		if numbers && v.number && json.Valid([]byte(v.text)) {
+			b.WriteString(v.text)
+		} else {
+			writeString(b, v.text)
+		}
+	}
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	b.Write(data)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gzipMagic is the two-byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openInput opens the file at path, or stdin when path is "-". Input that
+// starts with the gzip header is decompressed transparently, whatever its
+// name.
+func openInput(path string) (io.ReadCloser, error) {
+	var f io.ReadCloser = io.NopCloser(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+		f = file
+	}
+
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(len(gzipMagic)); !bytes.Equal(magic, gzipMagic) {
+		return readCloser{br, f}, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading gzip input: %w", err)
+	}
+	return readCloser{zr, multiCloser{zr, f}}, nil
+}
+
+// readCloser pairs a Reader with the Closer that releases what it reads from.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// multiCloser closes each of its Closers in order and returns the first error.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var first error
+	for _, c := range m {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe
+// or regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,922 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+const usage = `usage: hashira [recover] [flags] [path_to_json_file | -]...
+       hashira split -secret N -n N -k K -prime P [-base B]
+       hashira verify [flags] [path_to_json_file | -]...
+       hashira diff [flags] a.json b.json
+       hashira gen-vectors [-n N] [-dir DIR] [-seed S]
+
+recover, the default when no subcommand is given, reconstructs the secret.
+split generates shares, verify checks share files without printing the
+secret, diff compares the secrets of two share files and gen-vectors writes
+random share files with known secrets; run
+"hashira <command> -h" for their flags. A file named like a subcommand must
+be given as ./name.
+
+Reads the share file from stdin when no path is given or the path is "-".
+When several files are given, each is processed in turn and a failure in one
+does not stop the others. A JSON file whose top level is an array of share
+files, or that holds several share files one after another as in
+newline-delimited JSON, is handled the same way, with results labelled by
+position: file[0], file[1] and so on.
+
+Flags of recover:`
+
+// options holds the command-line flags.
+type options struct {
+	json      bool
+	k         int
+	base      int
+	consensus bool
+	stream    bool
+	use       []*big.Int
+	noVerify  bool
+	quiet     bool
+	timeout   time.Duration
+	serve     string
+	input     string
+	check     bool
+	rational  bool
+	verbose   bool
+	split     bool
+	secret    *big.Int
+	n         int
+	prime     *big.Int
+	maxPoints int
+	decimal   bool
+	format    string
+	seed      *int64
+	strict    bool
+	progress  bool
+	reduce    bool
+	selection string
+	valuePath []string
+	valueBase int
+	explain   bool
+	noSort    bool
+	bytes     bool
+	output    string
+	noClobber bool
+	maxErrors *int
+	swapXY    bool
+	timings   bool
+	stats     bool
+	coeffs    bool
+	// out is where results are written: stdout, or the -o file.
+	out io.Writer
+}
+
+// result is the outcome of reconstructing the secret from one share file.
+// With -allow-rational, a fractional secret is held in rational and secret
+// is nil.
+type result struct {
+	secret   *big.Int
+	rational *big.Rat
+	k        int
+	// used holds the points the secret was computed from or, with
+	// -consensus, every point that agrees with it.
+	used []hashira.Point
+}
+
+func main() {
+	err := run(os.Args[1:])
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(exitFailure)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// commands maps each subcommand to the function that runs it with the
+// remaining arguments.
+var commands = map[string]func(args []string) error{
+	"recover":     runRecover,
+	"split":       runSplitCommand,
+	"verify":      runVerify,
+	"diff":        runDiff,
+	"gen-vectors": runGenVectors,
+}
+
+func run(args []string) error {
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd(args[1:])
+		}
+	}
+	return runRecover(args)
+}
+
+// runRecover runs the recover subcommand. Its -split and -check flags are
+// kept from before subcommands existed.
+func runRecover(args []string) error {
+	var opts options
+	fs := flag.NewFlagSet("hashira", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), exitCodesUsage)
+	}
+	fs.BoolVar(&opts.json, "json", false, "print results as JSON instead of text")
+	fs.IntVar(&opts.k, "k", 0, "number of points to interpolate, 0 meaning keys.k from the file; with -split, the threshold")
+	fs.IntVar(&opts.base, "base", 10, "base (2-36) in which to print the secret")
+	fs.BoolVar(&opts.consensus, "consensus", false, "recover the secret most combinations of k points agree on, tolerating corrupt shares")
+	fs.BoolVar(&opts.stream, "stream", false, "stop decoding once k points are decoded and use those, in file order (ignored with -consensus or -use)")
+	fs.Func("use", "comma-separated x-coordinates of exactly k points to interpolate, e.g. 1,3,5", func(s string) error {
+		xs, err := parseXList(s)
+		opts.use = xs
+		return err
+	})
+	fs.BoolVar(&opts.noVerify, "no-verify", false, "skip checking that the points beyond the k used lie on the recovered polynomial")
+	fs.BoolVar(&opts.strict, "strict", false, "fail, rather than warn, if any point does not lie on the recovered polynomial")
+	fs.BoolVar(&opts.quiet, "quiet", false, "print only the secret, one line per file")
+	fs.BoolVar(&opts.bytes, "bytes", false, "write the secret as raw big-endian bytes, for secrets that encode binary data; several secrets are written back to back")
+	fs.BoolVar(&opts.coeffs, "coeffs", false, "with -json or -serve, also output the polynomial's coefficients as \"coefficients\", constant term first (not with -prime or -decimal)")
+	fs.BoolVar(&opts.stats, "stats", false, "print how many points were parsed, how many keys were skipped and how many points were used to stderr")
+	fs.BoolVar(&opts.timings, "timings", false, "print how long reading, decoding and interpolating each input took to stderr")
+	fs.BoolVar(&opts.progress, "progress", false, "with -consensus, show how many combinations have been evaluated on stderr")
+	fs.Func("max-errors", "with -consensus, search only for a secret that at most this many shares disagree with; with -prime, correct up to this many corrupt shares by Berlekamp-Welch decoding", func(s string) error {
+		e, err := strconv.Atoi(s)
+		opts.maxErrors = &e
+		return err
+	})
+	fs.DurationVar(&opts.timeout, "timeout", 0, "with -consensus, stop after this long and report the leading secret so far (0 means no limit)")
+	fs.StringVar(&opts.serve, "serve", "", "instead of reading files, serve reconstruction over HTTP on this address, e.g. :8080")
+	fs.StringVar(&opts.input, "input", "", "share file JSON given inline instead of as a file or on stdin")
+	fs.BoolVar(&opts.check, "check", false, "only validate each input (bases, x-coordinates, duplicates, point count) without computing the secret")
+	fs.BoolVar(&opts.rational, "allow-rational", false, "print a non-integer secret as num/den instead of failing (ignored with -consensus)")
+	fs.BoolVar(&opts.explain, "explain", false, fmt.Sprintf("print each step of the Lagrange computation to stderr, for k up to %d (not with -consensus or -prime)", maxExplainK))
+	fs.BoolVar(&opts.verbose, "v", false, "log each decoded point, the points chosen and the interpolation terms to stderr")
+	fs.BoolVar(&opts.split, "split", false, "instead of reconstructing, split -secret into -n shares as the split subcommand does")
+	secretFlag(fs, &opts, "with -split, the base-10 secret to split")
+	fs.IntVar(&opts.n, "n", 0, "with -split, the number of shares to generate")
+	seedFlag(fs, &opts, "with -split, generate shares from a math/rand source with this seed, for reproducible tests (NOT cryptographically secure); with -select random, the seed for choosing points")
+	fs.StringVar(&opts.selection, "select", "first", "which k points to interpolate when there are more: first, last or random (in x order unless -no-sort; random is seeded by -seed)")
+	fs.BoolVar(&opts.noSort, "no-sort", false, "keep the points in file order instead of sorting them by x, so -select first and last pick the shares as the file lists them")
+	prime := primeFlags(fs, "prime modulus of the field: the field to split over with -split, otherwise reconstruct over GF(prime) (skips verification)")
+	fs.BoolVar(&opts.reduce, "reduce", false, "with -prime, reduce y-values outside [0, prime) modulo the prime instead of failing")
+	fs.IntVar(&opts.maxPoints, "max-points", 10000, "reject inputs with more than this many points (0 means unlimited)")
+	formatFlag(fs, &opts)
+	valueFlags(fs, &opts)
+	outputFlags(fs, &opts)
+	fs.BoolVar(&opts.swapXY, "swap-xy", false, "treat each share's value as its x-coordinate and its key as y, for files written the other way round (not with -decimal)")
+	fs.BoolVar(&opts.decimal, "decimal", false, "accept values with a fractional part, such as 1.25, and report the secret as a reduced fraction (not with -consensus or -prime)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(opts.verbose))
+
+	if opts.base < 2 || opts.base > 36 {
+		return fmt.Errorf("-base %d out of range: must be between 2 and 36", opts.base)
+	}
+	if err := checkFormat(opts.format); err != nil {
+		return err
+	}
+	if opts.quiet && opts.json {
+		return errors.New("-quiet cannot be combined with -json")
+	}
+	if opts.bytes && (opts.quiet || opts.json || opts.check || opts.split) {
+		return errors.New("-bytes cannot be combined with -quiet, -json, -check or -split")
+	}
+	if opts.coeffs && !opts.json && opts.serve == "" {
+		return errors.New("-coeffs requires -json or -serve")
+	}
+	if opts.use != nil && opts.consensus {
+		return errors.New("-use cannot be combined with -consensus")
+	}
+	switch opts.selection {
+	case "first":
+	case "last", "random":
+		if opts.use != nil || opts.consensus || opts.stream {
+			return fmt.Errorf("-select %s cannot be combined with -use, -consensus or -stream", opts.selection)
+		}
+	default:
+		return fmt.Errorf("-select %q: must be first, last or random", opts.selection)
+	}
+
+	var err error
+	if opts.prime, err = prime(); err != nil {
+		return err
+	}
+	if opts.strict && (opts.noVerify || opts.prime != nil) {
+		return errors.New("-strict cannot be combined with -no-verify or -prime")
+	}
+	if opts.coeffs && (opts.prime != nil || opts.decimal) {
+		return errors.New("-coeffs cannot be combined with -prime or -decimal")
+	}
+	if opts.swapXY && opts.decimal {
+		return errors.New("-swap-xy cannot be combined with -decimal")
+	}
+	if opts.decimal && (opts.consensus || opts.prime != nil) {
+		return errors.New("-decimal cannot be combined with -consensus or -prime")
+	}
+	if opts.prime != nil && opts.consensus {
+		return errors.New("-prime cannot be combined with -consensus")
+	}
+	if opts.maxErrors != nil && !opts.consensus && opts.prime == nil {
+		return errors.New("-max-errors requires -consensus or -prime")
+	}
+	if opts.maxErrors != nil && opts.use != nil {
+		return errors.New("-max-errors cannot be combined with -use")
+	}
+	if opts.explain && (opts.consensus || opts.prime != nil) {
+		return errors.New("-explain cannot be combined with -consensus or -prime")
+	}
+
+	if opts.serve != "" {
+		if fs.NArg() > 0 {
+			return errors.New("-serve takes no file arguments")
+		}
+		if opts.output != "" {
+			return errors.New("-o cannot be combined with -serve")
+		}
+		return serve(opts.serve, opts)
+	}
+
+	var paths []string
+	if opts.split {
+		if fs.NArg() > 0 {
+			return errors.New("-split takes no file arguments")
+		}
+	} else if paths, err = inputPaths(fs, opts); err != nil {
+		return err
+	}
+
+	return withOutput(&opts, func() error {
+		switch {
+		case opts.split:
+			return runSplit(opts)
+		case opts.check:
+			return forEachInput(paths, opts, func(name string, r io.Reader) error {
+				return checkReader(r, name, opts, false)
+			})
+		}
+		if opts.bytes && opts.output == "" && isTerminal(os.Stdout) {
+			slog.Warn("writing the secret as raw bytes to a terminal; use -o or redirect stdout")
+		}
+		return forEachInput(paths, opts, func(name string, r io.Reader) error {
+			res, err := recoverReader(r, opts)
+			if err != nil {
+				return err
+			}
+			return printResult(res, name, opts)
+		})
+	})
+}
+
+// outputFlags registers -o and -no-clobber on fs.
+func outputFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.output, "o", "", "write results to this file instead of stdout, replacing any existing file")
+	fs.BoolVar(&opts.noClobber, "no-clobber", false, "with -o, fail instead of replacing an existing file")
+}
+
+// withOutput sets opts.out to the -o file, or stdout if there is none, and
+// calls fn. The file is closed once fn returns; an error closing it is
+// reported if fn succeeded.
+func withOutput(opts *options, fn func() error) error {
+	if opts.output == "" {
+		opts.out = os.Stdout
+		return fn()
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if opts.noClobber {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	f, err := os.OpenFile(opts.output, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening output: %w", err)
+	}
+	opts.out = f
+
+	err = fn()
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("writing output: %w", cerr)
+	}
+	return err
+}
+
+// forEachInput calls fn with each share file named by paths, or with the
+// -input JSON if it was given. fn receives an empty name when there is only
+// one input. With several inputs, a failure is printed and the remaining
+// inputs are still processed. An input holding a JSON array of share files,
+// or several share files one after another, is handled by forEachCase.
+func forEachInput(paths []string, opts options, fn func(name string, r io.Reader) error) error {
+	if opts.input != "" {
+		r, err := convertInput(strings.NewReader(opts.input), inputFormat("", opts))
+		if err != nil {
+			return err
+		}
+		return forEachCase("", r, fn)
+	}
+
+	process := func(path, name string) error {
+		rc, err := openInput(path)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		r, err := convertInput(rc, inputFormat(path, opts))
+		if err != nil {
+			return err
+		}
+		return forEachCase(name, r, fn)
+	}
+
+	if len(paths) == 1 {
+		return process(paths[0], "")
+	}
+
+	f := failures{what: "files"}
+	for _, path := range paths {
+		f.add(path, process(path, path))
+	}
+	return f.err()
+}
+
+// forEachCase calls fn with the share file read from r or, if r holds a
+// top-level JSON array, with each of its elements in turn as an independent
+// share file named name[i]. Likewise, several JSON documents one after
+// another, such as newline-delimited share sets appended over time, are
+// each passed to fn as name[i]. As with several files, a failed case is
+// printed and the rest are still processed. Each document is read in full
+// before fn sees it, so none is skipped when fn stops decoding early, as it
+// does with -stream.
+func forEachCase(name string, r io.Reader, fn func(name string, r io.Reader) error) error {
+	br := bufio.NewReader(r)
+	if startsArray(br) {
+		return forEachElement(name, br, fn)
+	}
+
+	dec := json.NewDecoder(br)
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		return &hashira.FormatError{Err: fmt.Errorf("parsing JSON: %w", err)}
+	}
+	if !dec.More() {
+		return fn(name, bytes.NewReader(first))
+	}
+
+	f := failures{what: "documents"}
+	label := name + "[0]"
+	f.add(label, fn(label, bytes.NewReader(first)))
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return &hashira.FormatError{Err: fmt.Errorf("parsing document %d: %w", f.total, err)}
+		}
+		label := fmt.Sprintf("%s[%d]", name, f.total)
+		f.add(label, fn(label, bytes.NewReader(raw)))
+	}
+	return f.err()
+}
+
+// forEachElement calls fn with each element of the JSON array read from br
+// as a share file named name[i]. An empty array is an error, so that an
+// empty batch does not pass for a successful one.
+func forEachElement(name string, br *bufio.Reader, fn func(name string, r io.Reader) error) error {
+	dec := json.NewDecoder(br)
+	if _, err := dec.Token(); err != nil {
+		return &hashira.FormatError{Err: fmt.Errorf("parsing JSON: %w", err)}
+	}
+	f := failures{what: "cases"}
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return &hashira.FormatError{Err: fmt.Errorf("parsing case %d: %w", f.total, err)}
+		}
+		label := fmt.Sprintf("%s[%d]", name, f.total)
+		f.add(label, fn(label, bytes.NewReader(raw)))
+	}
+	if _, err := dec.Token(); err != nil {
+		return &hashira.FormatError{Err: fmt.Errorf("parsing JSON: %w", err)}
+	}
+	if f.total == 0 {
+		return &hashira.FormatError{Err: errors.New("array contains no cases")}
+	}
+	return f.err()
+}
+
+// startsArray reports whether the next byte in br other than whitespace is
+// '[', leaving it unread.
+func startsArray(br *bufio.Reader) bool {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\n', '\r':
+			br.ReadByte()
+		default:
+			return b[0] == '['
+		}
+	}
+}
+
+// failures tallies the outcome of independent inputs, printing each error as
+// it is added.
+type failures struct {
+	what          string // what the inputs are, such as "files"
+	total, failed int
+	code          int // exit code shared by every failure, or exitFailure
+}
+
+func (f *failures) add(label string, err error) {
+	f.total++
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %s: %v\n", label, err)
+	if c := exitCode(err); f.failed == 0 {
+		f.code = c
+	} else if c != f.code {
+		f.code = exitFailure
+	}
+	f.failed++
+}
+
+// err returns nil if nothing failed, and otherwise a summary carrying the
+// exit code.
+func (f *failures) err() error {
+	if f.failed == 0 {
+		return nil
+	}
+	return &exitError{code: f.code, err: fmt.Errorf("%d of %d %s failed", f.failed, f.total, f.what)}
+}
+
+// recoverReader decodes a share file from r and reconstructs its secret.
+func recoverReader(r io.Reader, opts options) (*result, error) {
+	var t timings
+	tr := &timedReader{r: r}
+	if opts.timings {
+		r = tr
+	}
+
+	start := time.Now()
+	dec := hashira.NewDecoder(r)
+	dec.StopEarly = streaming(opts)
+	dec.Limit = opts.k
+	dec.MaxPoints = opts.maxPoints
+	dec.Decimals = opts.decimal
+	dec.ValuePath = opts.valuePath
+	dec.DefaultBase = opts.valueBase
+	in, err := dec.Decode()
+	t.read, t.decode = tr.wait, time.Since(start)-tr.wait
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(in)
+	if k := effectiveK(in.K, opts); k >= 1 && len(in.Points) < hashira.MinPointsRequired(k) {
+		return nil, &hashira.InsufficientPointsError{K: k, Points: len(in.Points)}
+	}
+	if in.N != 0 && in.N != len(in.Points) && !dec.StopEarly {
+		slog.Warn("keys.n does not match the number of points decoded", "n", in.N, "points", len(in.Points))
+	}
+
+	for _, p := range in.Points {
+		slog.Debug("decoded point", "point", p)
+	}
+
+	start = time.Now()
+	res, err := recoverInput(in, opts)
+	t.interpolate = time.Since(start)
+	if opts.timings {
+		t.print()
+	}
+	if opts.stats && err == nil {
+		fmt.Fprintf(os.Stderr, "parsed %d points, skipped %d, used %d\n", len(in.Points), len(in.Warnings), len(res.used))
+	}
+	return res, err
+}
+
+// streaming reports whether -stream applies: the decoder stops once it has k
+// points, unless every point is needed.
+func streaming(opts options) bool {
+	return opts.stream && !opts.consensus && opts.use == nil
+}
+
+// swapXY exchanges the coordinates of each of points in place, for -swap-xy.
+// It returns an error if two points have the same y, as they would then have
+// the same x after the swap.
+func swapXY(points []hashira.Point) error {
+	seen := make(map[string]*big.Int, len(points))
+	for i, p := range points {
+		if other, ok := seen[p.Y.String()]; ok {
+			return fmt.Errorf("-swap-xy: the shares at x=%s and x=%s both have y=%s, which cannot both be x-coordinates", other, p.X, p.Y)
+		}
+		seen[p.Y.String()] = p.X
+		points[i] = hashira.Point{X: p.Y, Y: p.X}
+	}
+	return nil
+}
+
+// logWarnings logs the parts of in that the decoder skipped.
+func logWarnings(in *hashira.Input) {
+	for _, w := range in.Warnings {
+		slog.Warn("skipping key", "key", w.Key, "err", w.Err)
+	}
+}
+
+// recoverInput reconstructs the secret from a decoded share file, first
+// transposing the points with -swap-xy, and undoes the scaling of decimal
+// values. A secret from decimal values may be a fraction.
+func recoverInput(in *hashira.Input, opts options) (*result, error) {
+	if opts.swapXY {
+		if err := swapXY(in.Points); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range in.Points {
+		if p.X.Sign() == 0 {
+			slog.Warn("share at x=0 holds the secret itself")
+		}
+	}
+
+	if in.Scale == nil {
+		return recoverPoints(in.Points, in.K, opts)
+	}
+
+	slog.Debug("decimal values scaled to integers", "scale", in.Scale)
+	opts.rational = true
+	res, err := recoverPoints(in.Points, in.K, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	value := res.rational
+	if value == nil {
+		value = new(big.Rat).SetInt(res.secret)
+	}
+	value.Quo(value, new(big.Rat).SetInt(in.Scale))
+	res.secret, res.rational = nil, nil
+	if value.IsInt() {
+		res.secret = value.Num()
+	} else {
+		res.rational = value
+	}
+	return res, nil
+}
+
+// checkFieldRange reports an error if the y-coordinate of any point lies
+// outside [0, prime), since shares over GF(prime) are normally written
+// reduced and an unreduced one suggests the wrong prime. With reduce, such
+// values are replaced by their residue instead.
+func checkFieldRange(points []hashira.Point, prime *big.Int, reduce bool) error {
+	for i, p := range points {
+		if p.Y.Sign() >= 0 && p.Y.Cmp(prime) < 0 {
+			continue
+		}
+		if !reduce {
+			return fmt.Errorf("share at x=%s: y=%s is outside [0, prime); use -reduce to reduce it modulo the prime", p.X, p.Y)
+		}
+		slog.Debug("reducing y modulo the prime", "x", p.X, "y", p.Y)
+		points[i].Y = new(big.Int).Mod(p.Y, prime)
+	}
+	return nil
+}
+
+// newLogger returns a logger writing to stderr that shows warnings and
+// results, or everything down to debug level if verbose is set.
+func newLogger(verbose bool) *slog.Logger {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// recoverPoints reconstructs the secret from points with threshold k, as
+// selected by opts. Unless -use selects them, the points used are the first k
+// in x order, or in file order with -no-sort; the decoder keeps the order of
+// the file in both layouts. The share set is checked up front with
+// hashira.ValidateShareSet, so duplicate x-coordinates are an error wherever
+// they appear.
+func recoverPoints(points []hashira.Point, k int, opts options) (*result, error) {
+	if !opts.noSort {
+		sort.SliceStable(points, func(i, j int) bool {
+			return points[i].X.Cmp(points[j].X) < 0
+		})
+	}
+
+	if opts.k != 0 {
+		if opts.k < 1 || opts.k > len(points) {
+			return nil, fmt.Errorf("-k %d out of range: must be between 1 and the number of points (%d)", opts.k, len(points))
+		}
+		k = opts.k
+	}
+
+	if err := hashira.ValidateShareSet(points, k); err != nil {
+		return nil, err
+	}
+	if opts.prime != nil {
+		if err := checkFieldRange(points, opts.prime, opts.reduce); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.consensus {
+		ctx := context.Background()
+		if opts.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+			defer cancel()
+		}
+		finish := func() {}
+		if opts.progress {
+			var update func(done, total int64)
+			update, finish = newProgress()
+			ctx = hashira.WithConsensusProgress(ctx, update)
+		}
+
+		var secret *big.Int
+		var used []hashira.Point
+		var err error
+		if opts.maxErrors != nil {
+			secret, used, err = hashira.RecoverSecretConsensusMaxErrors(ctx, points, k, *opts.maxErrors)
+		} else {
+			secret, used, err = hashira.RecoverSecretConsensusDetailed(ctx, points, k)
+		}
+		finish()
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) && secret != nil:
+			slog.Warn("consensus search timed out; reporting the leading secret so far", "timeout", opts.timeout)
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, fmt.Errorf("consensus search timed out after %v before any combination agreed", opts.timeout)
+		case err != nil:
+			return nil, err
+		}
+		if opts.strict && len(used) < len(points) {
+			return nil, &hashira.MismatchError{Points: notIn(points, used)}
+		}
+		return &result{secret: secret, k: k, used: used}, nil
+	}
+
+	if opts.prime != nil && opts.maxErrors != nil {
+		secret, err := hashira.RecoverWithErrorCorrectionMax(points, k, *opts.maxErrors, opts.prime)
+		if err != nil {
+			return nil, err
+		}
+		return &result{secret: secret, k: k, used: points}, nil
+	}
+
+	pointsToUse := points[:k]
+	switch {
+	case opts.use != nil:
+		var err error
+		if pointsToUse, err = selectPoints(points, opts.use, k); err != nil {
+			return nil, err
+		}
+	case opts.selection == "last":
+		pointsToUse = points[len(points)-k:]
+	case opts.selection == "random":
+		pointsToUse = randomPoints(points, k, opts.seed)
+	}
+
+	slog.Debug("interpolating", "k", k, "points", hashira.PointsString(pointsToUse))
+	if opts.prime != nil {
+		secret, err := hashira.RecoverSecretMod(pointsToUse, opts.prime)
+		if err != nil {
+			return nil, err
+		}
+		return &result{secret: secret, k: k, used: pointsToUse}, nil
+	}
+
+	if opts.explain {
+		if err := explain(os.Stderr, pointsToUse); err != nil {
+			return nil, err
+		}
+	}
+	if opts.verbose {
+		if err := logTerms(pointsToUse); err != nil {
+			return nil, err
+		}
+	}
+	secret, err := hashira.RecoverSecret(pointsToUse)
+	var nonInteger *hashira.NonIntegerError
+	if errors.As(err, &nonInteger) && opts.rational {
+		if !opts.noVerify {
+			if err := verifyRemaining(points, pointsToUse, opts.strict); err != nil {
+				return nil, err
+			}
+		}
+		slog.Warn("secret is not an integer; reporting it as a fraction")
+		return &result{rational: nonInteger.Value, k: k, used: pointsToUse}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !opts.noVerify {
+		if err := verifyRemaining(points, pointsToUse, opts.strict); err != nil {
+			return nil, err
+		}
+	}
+	if opts.verbose {
+		report, err := hashira.Report(usedFirst(points, pointsToUse), k)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprint(os.Stderr, report)
+	}
+	return &result{secret: secret, k: k, used: pointsToUse}, nil
+}
+
+// secretFlag registers -secret on fs, storing it in opts.secret.
+func secretFlag(fs *flag.FlagSet, opts *options, help string) {
+	fs.Func("secret", help, func(s string) error {
+		var err error
+		opts.secret, err = parseBigInt(s)
+		return err
+	})
+}
+
+// seedFlag registers -seed on fs, storing it in opts.seed.
+func seedFlag(fs *flag.FlagSet, opts *options, help string) {
+	fs.Func("seed", help, func(s string) error {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		opts.seed = &seed
+		return err
+	})
+}
+
+// primeFlags registers -prime and -prime-base on fs. After fs is parsed, the
+// returned function decodes the modulus and checks that it is prime; it
+// returns nil if -prime was not given.
+func primeFlags(fs *flag.FlagSet, help string) func() (*big.Int, error) {
+	prime := fs.String("prime", "", help)
+	primeBase := fs.Int("prime-base", 10, "base (2-62) in which -prime is written; a matching 0x, 0o or 0b prefix is also accepted")
+	return func() (*big.Int, error) {
+		if *prime == "" {
+			return nil, nil
+		}
+		p, err := hashira.DecodeValue(*prime, *primeBase)
+		if err != nil {
+			return nil, fmt.Errorf("-prime: %w", err)
+		}
+		if err := hashira.ValidatePrime(p); err != nil {
+			return nil, fmt.Errorf("-prime: %w", err)
+		}
+		return p, nil
+	}
+}
+
+// inputPaths returns the files to read, defaulting to stdin when none are
+// given and -input is not set. It fails if stdin is a terminal, since the
+// user most likely forgot the file argument.
+func inputPaths(fs *flag.FlagSet, opts options) ([]string, error) {
+	paths := fs.Args()
+	if opts.input != "" && len(paths) > 0 {
+		return nil, errors.New("-input cannot be combined with file arguments or stdin")
+	}
+	if opts.input == "" && len(paths) == 0 {
+		if isTerminal(os.Stdin) {
+			fs.Usage()
+			return nil, errors.New("no input given")
+		}
+		paths = []string{"-"}
+	}
+	return paths, nil
+}
+
+// parseBigInt parses a base-10 integer flag value.
+func parseBigInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+// parseXList parses a comma-separated list of base-10 x-coordinates.
+func parseXList(s string) ([]*big.Int, error) {
+	var xs []*big.Int
+	for _, field := range strings.Split(s, ",") {
+		x, ok := new(big.Int).SetString(strings.TrimSpace(field), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid x-coordinate %q", field)
+		}
+		xs = append(xs, x)
+	}
+	return xs, nil
+}
+
+// selectPoints returns the points whose x-coordinates are listed in xs, in
+// the order listed. Exactly k distinct x-coordinates must be given and each
+// must be present in points.
+func selectPoints(points []hashira.Point, xs []*big.Int, k int) ([]hashira.Point, error) {
+	if len(xs) != k {
+		return nil, fmt.Errorf("-use lists %d x-coordinates, need exactly k=%d", len(xs), k)
+	}
+
+	byX := make(map[string]hashira.Point, len(points))
+	for _, p := range points {
+		byX[p.X.String()] = p
+	}
+
+	selected := make([]hashira.Point, 0, k)
+	seen := make(map[string]bool, k)
+	for _, x := range xs {
+		key := x.String()
+		if seen[key] {
+			return nil, fmt.Errorf("-use lists x=%s more than once", key)
+		}
+		seen[key] = true
+
+		p, ok := byX[key]
+		if !ok {
+			return nil, fmt.Errorf("-use lists x=%s, which is not in the input", key)
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+// randomPoints returns k of points chosen at random, in their original
+// order. The choice is reproducible with seed; without one a seed is picked
+// and logged so that the run can be repeated.
+func randomPoints(points []hashira.Point, k int, seed *int64) []hashira.Point {
+	var s int64
+	if seed != nil {
+		s = *seed
+	} else {
+		s = time.Now().UnixNano()
+		slog.Info("selecting points at random", "seed", s)
+	}
+	chosen := rand.New(rand.NewSource(s)).Perm(len(points))[:k]
+	sort.Ints(chosen)
+
+	selected := make([]hashira.Point, k)
+	for i, j := range chosen {
+		selected[i] = points[j]
+	}
+	return selected
+}
+
+// verifyRemaining checks that the points not in used lie on the polynomial
+// through used, and prints a warning naming any that do not. With strict
+// set, such points are an error instead. The check does not depend on the
+// secret, so it applies equally to a fractional one.
+func verifyRemaining(points, used []hashira.Point, strict bool) error {
+	err := hashira.ValidateShareSetConsistent(usedFirst(points, used), len(used))
+	var mismatch *hashira.MismatchError
+	if errors.As(err, &mismatch) && !strict {
+		slog.Warn("verification failed", "err", mismatch)
+		return nil
+	}
+	return err
+}
+
+// usedFirst returns used followed by the points not in used, in their order
+// in points.
+func usedFirst(points, used []hashira.Point) []hashira.Point {
+	return append(append([]hashira.Point(nil), used...), notIn(points, used)...)
+}
+
+// notIn returns the points whose x-coordinates are not in used, in their
+// order in points.
+func notIn(points, used []hashira.Point) []hashira.Point {
+	inUse := make(map[string]bool, len(used))
+	for _, p := range used {
+		inUse[p.X.String()] = true
+	}
+	var rest []hashira.Point
+	for _, p := range points {
+		if !inUse[p.X.String()] {
+			rest = append(rest, p)
+		}
+	}
+	return rest
+}
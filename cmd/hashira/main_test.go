@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// binary is the hashira command built by TestMain for the tests to run.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "hashira-test")
+	if err != nil {
+		panic(err)
+	}
+	binary = filepath.Join(dir, "hashira")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		panic("building hashira: " + err.Error() + "\n" + string(out))
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runHashira runs the built binary with args and stdin, returning its
+// stdout, stderr and exit code.
+func runHashira(t *testing.T, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(binary, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	case err != nil:
+		t.Fatalf("running hashira %s: %v", strings.Join(args, " "), err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		want     string // expected stdout
+		wantCode int
+		wantErr  string // substring of stderr, if not empty
+	}{
+		{
+			name: "text output",
+			args: []string{"testdata/sample.json"},
+			want: "Successfully decoded points and calculated the secret.\n" +
+				"-----------------------------------------------------\n" +
+				"Secret (C): 3\n" +
+				"Points used (x): 1, 2, 3\n" +
+				"-----------------------------------------------------\n",
+		},
+		{
+			name: "quiet",
+			args: []string{"-quiet", "testdata/sample.json"},
+			want: "3\n",
+		},
+		{
+			name: "json",
+			args: []string{"-json", "testdata/sample.json"},
+			want: `{"secret":"3","k":3,"points_used":3,"used_x":["1","2","3"]}` + "\n",
+		},
+		{
+			name: "coefficients",
+			args: []string{"-json", "-coeffs", "testdata/sample.json"},
+			want: `{"secret":"3","k":3,"points_used":3,"used_x":["1","2","3"],"coefficients":["3","0","1"]}` + "\n",
+		},
+		{
+			name: "rational coefficients",
+			args: []string{"-json", "-coeffs", "-allow-rational", "testdata/fraction.json"},
+			want: `{"secret":"1/2","k":2,"points_used":2,"used_x":["1","3"],"coefficients":["1/2","1/2"]}` + "\n",
+		},
+		{
+			name:     "fractional coefficient",
+			args:     []string{"-json", "-coeffs"},
+			stdin:    `{"keys":{"k":3},"0":{"value":"1"},"1":{"value":"2"},"2":{"value":"4"}}`,
+			wantCode: exitNonInteger,
+			wantErr:  "coefficient of x^1 is non-integer: 1/2",
+		},
+		{
+			name:  "stdin",
+			args:  []string{"-quiet", "-base", "2"},
+			stdin: `{"keys":{"n":1,"k":1},"1":{"base":"10","value":"5"}}`,
+			want:  "101\n",
+		},
+		{
+			name:  "value base",
+			args:  []string{"-quiet", "-value-base", "16"},
+			stdin: `{"keys":{"n":2,"k":2},"1":{"value":"a"},"2":{"base":"10","value":"12"}}`,
+			want:  "8\n",
+		},
+		{
+			name:  "swap xy",
+			args:  []string{"-quiet", "-swap-xy"},
+			stdin: `{"keys":{"k":2},"5":{"base":"10","value":"1"},"7":{"base":"10","value":"2"}}`,
+			want:  "3\n",
+		},
+		{
+			name:     "swap xy with a repeated value",
+			args:     []string{"-swap-xy"},
+			stdin:    `{"keys":{"k":2},"5":{"base":"10","value":"1"},"7":{"base":"10","value":"1"}}`,
+			wantCode: exitFailure,
+			wantErr:  "both have y=1",
+		},
+		{
+			name:    "timings",
+			args:    []string{"-quiet", "-timings", "testdata/sample.json"},
+			want:    "3\n",
+			wantErr: "timings: read ",
+		},
+		{
+			name:    "stats",
+			args:    []string{"-quiet", "-stats"},
+			stdin:   `{"keys":{"k":1},"comment":"hand-edited","1":{"value":"4"},"2":{"value":"4"}}`,
+			want:    "4\n",
+			wantErr: "parsed 2 points, skipped 1, used 1\n",
+		},
+		{
+			name: "yaml",
+			args: []string{"-quiet", "testdata/sample.yaml"},
+			want: "3\n",
+		},
+		{
+			name: "several files",
+			args: []string{"-quiet", "testdata/sample.json", "testdata/sample.yaml"},
+			want: "3\n3\n",
+		},
+		{
+			name: "allow rational",
+			args: []string{"-quiet", "-allow-rational", "testdata/fraction.json"},
+			want: "1/2\n",
+		},
+		{
+			name:  "decimal values",
+			args:  []string{"-quiet", "-decimal"},
+			stdin: `{"keys":{"k":2},"1":{"value":"1.5"},"2":{"value":"2.25"}}`,
+			want:  "3/4\n",
+		},
+		{
+			name:  "decimal values with an integer secret",
+			args:  []string{"-quiet", "-decimal"},
+			stdin: `{"keys":{"k":2},"1":{"value":"1.5"},"2":{"value":"0"}}`,
+			want:  "3\n",
+		},
+		{
+			name:  "check decimal values",
+			args:  []string{"-check", "-decimal"},
+			stdin: `{"keys":{"k":2},"1":{"value":"1.5"},"2":{"value":"2.25"}}`,
+			want:  "input: OK (2 points, k=2)\n",
+		},
+		{
+			name:    "allow rational with a share off the polynomial",
+			args:    []string{"-quiet", "-allow-rational"},
+			stdin:   `{"keys":{"k":2},"1":{"value":"1"},"3":{"value":"2"},"5":{"value":"9"}}`,
+			want:    "1/2\n",
+			wantErr: "verification failed",
+		},
+		{
+			name:     "allow rational with -strict",
+			args:     []string{"-quiet", "-allow-rational", "-strict"},
+			stdin:    `{"keys":{"k":2},"1":{"value":"1"},"3":{"value":"2"},"5":{"value":"9"}}`,
+			wantCode: exitFailure,
+			wantErr:  "1 points do not lie on the reconstructed polynomial: x=5",
+		},
+		{
+			name:  "array of cases",
+			args:  []string{"-json"},
+			stdin: `[{"keys":{"k":1},"1":{"value":"7"}},{"keys":{"k":1},"1":{"value":"8"}}]`,
+			want: `{"file":"[0]","secret":"7","k":1,"points_used":1,"used_x":["1"]}` + "\n" +
+				`{"file":"[1]","secret":"8","k":1,"points_used":1,"used_x":["1"]}` + "\n",
+		},
+		{
+			name:     "empty array",
+			stdin:    " [ ] ",
+			wantCode: exitParse,
+			wantErr:  "array contains no cases",
+		},
+		{
+			name:  "concatenated documents",
+			args:  []string{"-quiet"},
+			stdin: `{"keys":{"k":1},"1":{"value":"7"}}` + "\n" + `{"keys":{"k":1},"1":{"value":"8"}}` + "\n",
+			want:  "7\n8\n",
+		},
+		{
+			name:     "concatenated documents with -stream",
+			args:     []string{"-quiet", "-stream"},
+			stdin:    `{"keys":{"k":1},"1":{"value":"7"},"2":{"value":"9"}}` + "\n" + `{"keys":{"k":2},"1":{"value":"8"}}` + "\n",
+			want:     "7\n",
+			wantCode: exitInsufficient,
+			wantErr:  "[1]: not enough points (1) to meet requirement k=2",
+		},
+		{
+			name:     "trailing data",
+			args:     []string{"-quiet"},
+			stdin:    `{"keys":{"k":1},"1":{"value":"7"}} xx`,
+			want:     "7\n",
+			wantCode: exitParse,
+			wantErr:  "parsing document 1",
+		},
+		{
+			name: "verify",
+			args: []string{"verify", "testdata/sample.json"},
+			want: "input: OK (4 points, k=3)\n",
+		},
+		{
+			name: "max errors",
+			args: []string{"-quiet", "-consensus", "-max-errors", "2", "testdata/corrupt.json"},
+			want: "3\n",
+		},
+		{
+			name: "max errors over a field",
+			args: []string{"-quiet", "-prime", "101", "-max-errors", "2", "testdata/corrupt.json"},
+			want: "3\n",
+		},
+		{
+			name:     "over the error budget",
+			args:     []string{"-quiet", "-consensus", "-max-errors", "1", "testdata/corrupt.json"},
+			wantCode: exitFailure,
+			wantErr:  "more than 1 corrupted shares",
+		},
+		{
+			name: "diff same",
+			args: []string{"diff", "testdata/sample.json", "testdata/sample.yaml"},
+			want: "same secret: 3\n",
+		},
+		{
+			name: "diff cases",
+			args: []string{"diff", "testdata/cases.json", "testdata/cases-changed.json"},
+			want: "case [0]: secrets differ:\n" +
+				"  testdata/cases.json[0]: 7\n" +
+				"  testdata/cases-changed.json[0]: 6\n" +
+				"case [1]: same secret: 8\n",
+			wantCode: exitFailure,
+			wantErr:  "secrets differ in 1 of 2 cases",
+		},
+		{
+			name:     "diff case counts",
+			args:     []string{"diff", "testdata/cases.json", "testdata/sample.json"},
+			wantCode: exitFailure,
+			wantErr:  "testdata/cases.json holds 2 cases but testdata/sample.json holds 1",
+		},
+		{
+			name:     "composite prime",
+			args:     []string{"-prime", "100", "testdata/sample.json"},
+			wantCode: exitFailure,
+			wantErr:  "-prime: modulus 100 is not prime",
+		},
+		{
+			name:     "usage error",
+			args:     []string{"-no-such-flag", "testdata/sample.json"},
+			wantCode: exitFailure,
+			wantErr:  "flag provided but not defined",
+		},
+		{
+			name:     "parse error",
+			args:     []string{"testdata/truncated.json"},
+			wantCode: exitParse,
+			wantErr:  "parsing JSON",
+		},
+		{
+			name:     "non-integer secret",
+			args:     []string{"testdata/fraction.json"},
+			wantCode: exitNonInteger,
+			wantErr:  "non-integer: 1/2",
+		},
+		{
+			name:     "too few points",
+			args:     []string{"testdata/short.json"},
+			wantCode: exitInsufficient,
+			wantErr:  "not enough points (2) to meet requirement k=3",
+		},
+		{
+			name:     "mixed failures",
+			args:     []string{"-quiet", "testdata/fraction.json", "testdata/short.json", "testdata/sample.json"},
+			want:     "3\n",
+			wantCode: exitFailure,
+			wantErr:  "2 of 3 files failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runHashira(t, tt.stdin, tt.args...)
+			if stdout != tt.want {
+				t.Errorf("stdout:\n%s\nwant:\n%s", stdout, tt.want)
+			}
+			if code != tt.wantCode {
+				t.Errorf("exit code %d, want %d; stderr:\n%s", code, tt.wantCode, stderr)
+			}
+			if !strings.Contains(stderr, tt.wantErr) {
+				t.Errorf("stderr:\n%s\nwant it to contain %q", stderr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if _, stderr, code := runHashira(t, "", "-quiet", "-o", path, "testdata/sample.json"); code != 0 {
+		t.Fatalf("exit code %d; stderr:\n%s", code, stderr)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "3\n" {
+		t.Errorf("output file holds %q, %v; want \"3\\n\"", data, err)
+	}
+
+	_, stderr, code := runHashira(t, "", "-quiet", "-o", path, "-no-clobber", "testdata/sample.json")
+	if code == 0 || !strings.Contains(stderr, "file exists") {
+		t.Errorf("-no-clobber over an existing file: exit code %d, stderr:\n%s", code, stderr)
+	}
+}
+
+func TestGzipInput(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The header is detected whatever the file is called.
+	path := filepath.Join(t.TempDir(), "shares")
+	if err := os.WriteFile(path, compressed.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if stdout, stderr, code := runHashira(t, "", "-quiet", path); stdout != "3\n" || code != 0 {
+		t.Errorf("gzip file: stdout %q, exit code %d; stderr:\n%s", stdout, code, stderr)
+	}
+	if stdout, stderr, code := runHashira(t, compressed.String(), "-quiet"); stdout != "3\n" || code != 0 {
+		t.Errorf("gzip stdin: stdout %q, exit code %d; stderr:\n%s", stdout, code, stderr)
+	}
+
+	truncated := compressed.Bytes()[:compressed.Len()/2]
+	if _, stderr, code := runHashira(t, string(truncated), "-quiet"); code == 0 {
+		t.Errorf("truncated gzip stdin: exit code 0; stderr:\n%s", stderr)
+	}
+}
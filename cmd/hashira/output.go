@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+// jsonResult is the machine-readable form of a result printed by -json.
+type jsonResult struct {
+	File       string   `json:"file,omitempty"`
+	Secret     string   `json:"secret"`
+	K          int      `json:"k"`
+	PointsUsed int      `json:"points_used"`
+	UsedX      []string `json:"used_x"`
+	// Coefficients holds the polynomial's coefficients with -coeffs,
+	// constant term first, so that Coefficients[d] is that of x^d.
+	Coefficients []string `json:"coefficients,omitempty"`
+}
+
+// newJSONResult returns the -json form of res.
+func newJSONResult(res *result, file string, base int) jsonResult {
+	return jsonResult{
+		File:       file,
+		Secret:     secretText(res, base),
+		K:          res.k,
+		PointsUsed: len(res.used),
+		UsedX:      usedX(res),
+	}
+}
+
+// jsonOutput returns the -json form of res with the fields opts asks for,
+// which with -coeffs include the coefficients. It is shared by -json and
+// -serve.
+func jsonOutput(res *result, file string, opts options) (jsonResult, error) {
+	out := newJSONResult(res, file, opts.base)
+	if opts.coeffs {
+		var err error
+		if out.Coefficients, err = coefficientsText(res, opts); err != nil {
+			return jsonResult{}, err
+		}
+	}
+	return out, nil
+}
+
+// printResult writes res to opts.out. file names the input it came from and is
+// empty when only one input is being processed.
+func printResult(res *result, file string, opts options) error {
+	if opts.bytes {
+		if res.rational != nil || res.secret.Sign() < 0 {
+			return fmt.Errorf("-bytes needs a non-negative integer secret, got %s", secretText(res, 10))
+		}
+		_, err := opts.out.Write(res.secret.Bytes())
+		return err
+	}
+
+	if opts.json {
+		out, err := jsonOutput(res, file, opts)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(opts.out).Encode(out)
+	}
+
+	if opts.quiet {
+		fmt.Fprintln(opts.out, secretText(res, opts.base))
+		return nil
+	}
+
+	w := opts.out
+	if file != "" {
+		fmt.Fprintf(w, "==> %s <==\n", file)
+	}
+	fmt.Fprintln(w, "Successfully decoded points and calculated the secret.")
+	fmt.Fprintln(w, "-----------------------------------------------------")
+	fmt.Fprintf(w, "Secret (C): %s\n", secretText(res, opts.base))
+	fmt.Fprintf(w, "Points used (x): %s\n", strings.Join(usedX(res), ", "))
+	fmt.Fprintln(w, "-----------------------------------------------------")
+	return nil
+}
+
+// secretText formats the secret of res in base, as "num/den" if it is a
+// fraction. big.Rat keeps fractions reduced with the sign on the numerator,
+// so the denominator is always positive, and a whole number such as 3/1 is
+// written without one.
+func secretText(res *result, base int) string {
+	if res.rational != nil {
+		return ratText(res.rational, base)
+	}
+	return res.secret.Text(base)
+}
+
+// ratText formats r in base as "num/den", or without the denominator if r is
+// a whole number.
+func ratText(r *big.Rat, base int) string {
+	if r.IsInt() {
+		return r.Num().Text(base)
+	}
+	return r.Num().Text(base) + "/" + r.Denom().Text(base)
+}
+
+// coefficientsText returns the coefficients of the polynomial through the
+// first k points res was computed from, constant term first, in opts.base.
+// They must be integers unless -allow-rational is set, in which case a
+// fractional one is written as "num/den".
+func coefficientsText(res *result, opts options) ([]string, error) {
+	points := res.used[:res.k]
+	var text []string
+	if opts.rational {
+		for _, c := range hashira.RecoverCoefficientsRat(points) {
+			text = append(text, ratText(c, opts.base))
+		}
+		return text, nil
+	}
+
+	coeffs, err := hashira.RecoverCoefficients(points)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range coeffs {
+		text = append(text, c.Text(opts.base))
+	}
+	return text, nil
+}
+
+// usedX returns the x-coordinates of the points res was computed from, in
+// decimal.
+func usedX(res *result) []string {
+	xs := make([]string, len(res.used))
+	for i, p := range res.used {
+		xs[i] = p.X.String()
+	}
+	return xs
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSecretTextRational(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		base     int
+		want     string
+	}{
+		{1, 2, 10, "1/2"},
+		{-1, 2, 10, "-1/2"},
+		{1, -2, 10, "-1/2"},
+		{-6, -4, 10, "3/2"},
+		{6, 4, 10, "3/2"},
+		{3, 1, 10, "3"},
+		{-12, 4, 10, "-3"},
+		{0, 5, 10, "0"},
+		{255, 16, 16, "ff/10"},
+	}
+	for _, tt := range tests {
+		res := &result{rational: big.NewRat(tt.num, tt.den)}
+		if got := secretText(res, tt.base); got != tt.want {
+			t.Errorf("secretText(%d/%d, base %d) = %q, want %q", tt.num, tt.den, tt.base, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// progressInterval is the least time between two progress updates.
+const progressInterval = 200 * time.Millisecond
+
+// newProgress returns a callback for hashira.WithConsensusProgress that
+// rewrites a line on stderr such as "evaluated 1200/3003 combinations (40%)"
+// at most every progressInterval, and a function that prints the final
+// count and ends the line once the search is over.
+func newProgress() (update func(done, total int64), finish func()) {
+	var last time.Time
+	var done, total int64
+	print := func() {
+		fmt.Fprintf(os.Stderr, "\revaluated %d/%d combinations (%.0f%%)", done, total, 100*float64(done)/float64(total))
+	}
+
+	update = func(d, t int64) {
+		done, total = d, t
+		if now := time.Now(); now.Sub(last) >= progressInterval {
+			last = now
+			print()
+		}
+	}
+	finish = func() {
+		if total > 0 {
+			print()
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+	return update, finish
+}
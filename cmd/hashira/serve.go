@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+// maxRequestBytes bounds the size of a share file accepted by the server.
+const maxRequestBytes = 10 << 20
+
+// serve answers reconstruction requests on addr until the server fails. Each
+// POST body is a share file; the response is the result as printed by -json.
+func serve(addr string, opts options) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		handleRecover(w, r, opts)
+	})
+	slog.Info("listening", "addr", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func handleRecover(w http.ResponseWriter, r *http.Request, opts options) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST with a share file as the body"))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	dec := hashira.NewDecoder(bytes.NewReader(data))
+	dec.MaxPoints = opts.maxPoints
+	dec.Decimals = opts.decimal
+	dec.ValuePath = opts.valuePath
+	dec.DefaultBase = opts.valueBase
+	in, err := dec.Decode()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	logWarnings(in)
+
+	res, err := recoverInput(in, opts)
+	var out jsonResult
+	if err == nil {
+		out, err = jsonOutput(res, "", opts)
+	}
+	var nonInteger *hashira.NonIntegerError
+	switch {
+	case errors.As(err, &nonInteger):
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
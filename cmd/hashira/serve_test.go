@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       options
+		method     string
+		body       string
+		wantStatus int
+		want       string // substring of the response body
+	}{
+		{
+			name:       "recovered",
+			method:     http.MethodPost,
+			body:       `{"keys":{"n":3,"k":3},"1":{"base":"10","value":"4"},"2":{"base":"10","value":"7"},"3":{"base":"10","value":"12"}}`,
+			wantStatus: http.StatusOK,
+			want:       `{"secret":"3","k":3,"points_used":3,"used_x":["1","2","3"]}`,
+		},
+		{
+			name:       "bad JSON",
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":1},`,
+			wantStatus: http.StatusBadRequest,
+			want:       "parsing JSON",
+		},
+		{
+			name:       "too few points",
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":3},"1":{"base":"10","value":"4"}}`,
+			wantStatus: http.StatusBadRequest,
+			want:       "not enough points",
+		},
+		{
+			name:       "not POST",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			want:       "use POST",
+		},
+		{
+			name:       "body too large",
+			method:     http.MethodPost,
+			body:       strings.Repeat(" ", maxRequestBytes+1),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			want:       "too large",
+		},
+		{
+			name:       "non-integer secret",
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":2},"1":{"base":"10","value":"1"},"3":{"base":"10","value":"2"}}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       "non-integer: 1/2",
+		},
+		{
+			name:       "swap xy",
+			opts:       options{swapXY: true},
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":2},"5":{"base":"10","value":"1"},"7":{"base":"10","value":"2"}}`,
+			wantStatus: http.StatusOK,
+			want:       `"secret":"3"`,
+		},
+		{
+			name:       "swap xy with a repeated value",
+			opts:       options{swapXY: true},
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":2},"5":{"base":"10","value":"1"},"7":{"base":"10","value":"1"}}`,
+			wantStatus: http.StatusBadRequest,
+			want:       "both have y=1",
+		},
+		{
+			name:       "coefficients",
+			opts:       options{coeffs: true},
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":3},"1":{"base":"10","value":"4"},"2":{"base":"10","value":"7"},"3":{"base":"10","value":"12"}}`,
+			wantStatus: http.StatusOK,
+			want:       `"coefficients":["3","0","1"]`,
+		},
+		{
+			name:       "rational coefficients",
+			opts:       options{coeffs: true, rational: true},
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":2},"1":{"base":"10","value":"1"},"3":{"base":"10","value":"2"}}`,
+			wantStatus: http.StatusOK,
+			want:       `"coefficients":["1/2","1/2"]`,
+		},
+		{
+			name:       "fractional coefficient",
+			opts:       options{coeffs: true},
+			method:     http.MethodPost,
+			body:       `{"keys":{"k":3},"0":{"base":"10","value":"1"},"1":{"base":"10","value":"2"},"2":{"base":"10","value":"4"}}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       "coefficient of x^1 is non-integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			opts := tt.opts
+			opts.base = 10
+			handleRecover(rec, req, opts)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d; body: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body %q does not contain %q", rec.Body, tt.want)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type %q, want application/json", ct)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+const splitUsage = `usage: hashira split -secret N -n N -k K -prime P [-base B]
+
+Splits the secret into n shares over GF(prime), any k of which recover it,
+and prints them as a share file. Recover with "hashira -prime P".
+
+Flags:`
+
+// runSplitCommand runs the split subcommand.
+func runSplitCommand(args []string) error {
+	var opts options
+	fs := flag.NewFlagSet("hashira split", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), splitUsage)
+		fs.PrintDefaults()
+	}
+	secretFlag(fs, &opts, "the base-10 secret to split")
+	fs.IntVar(&opts.n, "n", 0, "number of shares to generate")
+	fs.IntVar(&opts.k, "k", 0, "number of shares needed to recover the secret")
+	fs.IntVar(&opts.base, "base", 10, "base (2-36) in which to write the share values")
+	prime := primeFlags(fs, "prime modulus of the field to split over")
+	seedFlag(fs, &opts, "generate shares from a math/rand source with this seed, for reproducible tests; NOT cryptographically secure")
+	outputFlags(fs, &opts)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(false))
+
+	if fs.NArg() > 0 {
+		return errors.New("split takes no file arguments")
+	}
+	if opts.base < 2 || opts.base > 36 {
+		return fmt.Errorf("-base %d out of range: must be between 2 and 36", opts.base)
+	}
+	var err error
+	if opts.prime, err = prime(); err != nil {
+		return err
+	}
+	return withOutput(&opts, func() error { return runSplit(opts) })
+}
+
+// runSplit splits the -secret into -n shares over GF(-prime), any -k of
+// which recover it, and prints them as a share file in the keyed layout with
+// values in -base. The file reads back with -prime.
+func runSplit(opts options) error {
+	if opts.secret == nil {
+		return errors.New("splitting requires -secret")
+	}
+	if opts.prime == nil {
+		return errors.New("splitting requires -prime")
+	}
+
+	random := cryptorand.Reader
+	if opts.seed != nil {
+		slog.Warn("generating shares from a seeded source; they are reproducible and not secure")
+		random = rand.New(rand.NewSource(*opts.seed))
+	}
+	shares, err := hashira.SplitSecretRand(random, opts.secret, opts.n, opts.k, opts.prime)
+	if err != nil {
+		return err
+	}
+
+	_, err = opts.out.Write(formatShares(shares, opts.k, opts.base))
+	return err
+}
+
+// formatShares renders shares as a share file in the keyed layout, with the
+// entries in x order rather than the sorted key order of encoding/json.
+func formatShares(shares []hashira.Point, k, base int) []byte {
+	bases := make([]int, len(shares))
+	for i := range bases {
+		bases[i] = base
+	}
+	return formatSharesBases(shares, k, bases)
+}
+
+// formatSharesBases is formatShares with the value of shares[i] written in
+// bases[i].
+func formatSharesBases(shares []hashira.Point, k int, bases []int) []byte {
+	var b bytes.Buffer
+	b.WriteString("{\n")
+	fmt.Fprintf(&b, "  \"keys\": {\"n\": %d, \"k\": %d}", len(shares), k)
+	for i, p := range shares {
+		fmt.Fprintf(&b, ",\n  \"%s\": {\"base\": \"%d\", \"value\": \"%s\"}", p.X, bases[i], p.Y.Text(bases[i]))
+	}
+	b.WriteString("\n}\n")
+	return b.Bytes()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// timedReader wraps an io.Reader, adding up the time spent waiting in Read.
+// Subtracted from the time taken to decode a share file, it separates
+// reading the input from parsing it.
+type timedReader struct {
+	r    io.Reader
+	wait time.Duration
+}
+
+func (t *timedReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.r.Read(p)
+	t.wait += time.Since(start)
+	return n, err
+}
+
+// timings holds how long each phase of recovering one share file took, for
+// -timings. The decoder parses the JSON and decodes each value in a single
+// streaming pass, so those are timed together.
+type timings struct {
+	read, decode, interpolate time.Duration
+}
+
+// print writes t to stderr on one line.
+func (t timings) print() {
+	fmt.Fprintf(os.Stderr, "timings: read %v, parse and decode %v, interpolate %v\n", t.read, t.decode, t.interpolate)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nefrttPrabhu/hashira"
+)
+
+const genVectorsUsage = `usage: hashira gen-vectors [-n N] [-dir DIR] [-seed S]
+
+Writes N share files with random polynomials, thresholds and bases to DIR,
+as regression fixtures and examples of the input format. Each case-NN.json
+has a sibling case-NN.secret holding the secret it recovers to. Existing
+files of the same names are replaced.
+
+Flags:`
+
+// runGenVectors runs the gen-vectors subcommand.
+func runGenVectors(args []string) error {
+	var opts options
+	fs := flag.NewFlagSet("hashira gen-vectors", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), genVectorsUsage)
+		fs.PrintDefaults()
+	}
+	count := fs.Int("n", 10, "number of share files to generate")
+	dir := fs.String("dir", "vectors", "directory to write the files to, created if missing")
+	seedFlag(fs, &opts, "seed for the random cases, so the same seed gives the same files; by default a seed is picked and logged")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(false))
+
+	if fs.NArg() > 0 {
+		return errors.New("gen-vectors takes no file arguments")
+	}
+	if *count < 1 {
+		return fmt.Errorf("-n %d: must be at least 1", *count)
+	}
+
+	var seed int64
+	if opts.seed != nil {
+		seed = *opts.seed
+	} else {
+		seed = time.Now().UnixNano()
+		slog.Info("generating test vectors", "seed", seed)
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+	for i := 1; i <= *count; i++ {
+		shares, bases, k, secret, err := randomCase(rng)
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(*dir, fmt.Sprintf("case-%02d", i))
+		if err := os.WriteFile(name+".json", formatSharesBases(shares, k, bases), 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name+".secret", []byte(secret.String()+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// randomCase returns the shares of a random polynomial with integer
+// coefficients of up to 64 bits and either sign, a base in which to write
+// each share, the threshold and the secret. There are between k and k+4
+// shares at distinct x-coordinates in 1..3n, written in random order.
+func randomCase(rng *rand.Rand) (shares []hashira.Point, bases []int, k int, secret *big.Int, err error) {
+	k = 1 + rng.Intn(8)
+	n := k + rng.Intn(5)
+
+	coeffs := make([]*big.Int, k)
+	for d := range coeffs {
+		coeffs[d] = new(big.Int).SetUint64(rng.Uint64())
+		if rng.Intn(2) == 0 {
+			coeffs[d].Neg(coeffs[d])
+		}
+	}
+
+	xs := rng.Perm(3 * n)[:n]
+	shares = make([]hashira.Point, n)
+	bases = make([]int, n)
+	for i, x := range xs {
+		xi := big.NewInt(int64(x + 1))
+		y := new(big.Int)
+		for d := k - 1; d >= 0; d-- {
+			y.Mul(y, xi).Add(y, coeffs[d])
+		}
+		shares[i] = hashira.Point{X: xi, Y: y}
+		bases[i] = 2 + rng.Intn(35)
+	}
+
+	// The shares are consistent by construction; recovering the secret from
+	// them guards against a generator bug producing a bad fixture.
+	got, err := hashira.RecoverSecret(shares[:k])
+	if err != nil || got.Cmp(coeffs[0]) != 0 {
+		return nil, nil, 0, nil, fmt.Errorf("generated case does not recover its secret: got %v, %v", got, err)
+	}
+	return shares, bases, k, coeffs[0], nil
+}
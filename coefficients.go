@@ -0,0 +1,130 @@
+package hashira
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RecoverCoefficients returns the coefficients of the unique polynomial of
+// degree len(points)-1 passing through points, ordered from the constant term
+// up to the highest degree. It returns an error if any coefficient is not an
+// integer.
+func RecoverCoefficients(points []Point) ([]*big.Int, error) {
+	if err := validatePoints(points); err != nil {
+		return nil, err
+	}
+	return integerCoefficients(lagrangeCoefficients(points))
+}
+
+// RecoverCoefficientsRat is RecoverCoefficients with the coefficients as
+// exact fractions, for when the points may not lie on a polynomial with
+// integer coefficients. It returns nil if points is empty or two points
+// share an x-coordinate.
+func RecoverCoefficientsRat(points []Point) []*big.Rat {
+	if validatePoints(points) != nil {
+		return nil
+	}
+	return lagrangeCoefficients(points)
+}
+
+// lagrangeCoefficients expands the Lagrange form of the polynomial through
+// points into its coefficients, constant term first.
+func lagrangeCoefficients(points []Point) []*big.Rat {
+	k := len(points)
+
+	coeffs := make([]*big.Rat, k)
+	for d := 0; d < k; d++ {
+		coeffs[d] = new(big.Rat)
+	}
+
+	for i := 0; i < k; i++ {
+		xi := new(big.Rat).SetInt(points[i].X)
+
+		// basis holds the coefficients of the product of (x - x_j) for j != i.
+		basis := []*big.Rat{new(big.Rat).SetInt64(1)}
+		denominator := new(big.Rat).SetInt64(1)
+
+		for j := 0; j < k; j++ {
+			if i == j {
+				continue
+			}
+			xj := new(big.Rat).SetInt(points[j].X)
+			basis = mulLinear(basis, xj)
+			diff := new(big.Rat).Sub(xi, xj)
+			denominator.Mul(denominator, diff)
+		}
+
+		scale := new(big.Rat).SetInt(points[i].Y)
+		scale.Quo(scale, denominator)
+		for d, c := range basis {
+			term := new(big.Rat).Mul(c, scale)
+			coeffs[d].Add(coeffs[d], term)
+		}
+	}
+	return coeffs
+}
+
+// PolynomialString renders the polynomial with coefficients coeffs, ordered
+// from the constant term up as RecoverCoefficients returns them, with the
+// highest degree first, such as "3x^2 - x + 7". Zero terms are left out and
+// the zero polynomial is "0".
+func PolynomialString(coeffs []*big.Int) string {
+	var b strings.Builder
+	for d := len(coeffs) - 1; d >= 0; d-- {
+		c := coeffs[d]
+		if c.Sign() == 0 {
+			continue
+		}
+		switch {
+		case b.Len() == 0 && c.Sign() < 0:
+			b.WriteByte('-')
+		case b.Len() > 0 && c.Sign() < 0:
+			b.WriteString(" - ")
+		case b.Len() > 0:
+			b.WriteString(" + ")
+		}
+
+		abs := new(big.Int).Abs(c)
+		if d == 0 || !abs.IsInt64() || abs.Int64() != 1 {
+			b.WriteString(abs.String())
+		}
+		switch {
+		case d == 1:
+			b.WriteByte('x')
+		case d > 1:
+			fmt.Fprintf(&b, "x^%d", d)
+		}
+	}
+	if b.Len() == 0 {
+		return "0"
+	}
+	return b.String()
+}
+
+// integerCoefficients converts coeffs to integers, returning an error naming
+// the first coefficient that is not integral.
+func integerCoefficients(coeffs []*big.Rat) ([]*big.Int, error) {
+	result := make([]*big.Int, len(coeffs))
+	for d, c := range coeffs {
+		if !c.IsInt() {
+			return nil, &NonIntegerError{What: fmt.Sprintf("coefficient of x^%d", d), Value: c}
+		}
+		result[d] = new(big.Int).Set(c.Num())
+	}
+	return result, nil
+}
+
+// mulLinear returns the coefficients of p(x) * (x - root), where p is given
+// from the constant term up.
+func mulLinear(p []*big.Rat, root *big.Rat) []*big.Rat {
+	out := make([]*big.Rat, len(p)+1)
+	for d := range out {
+		out[d] = new(big.Rat)
+	}
+	for d, c := range p {
+		out[d+1].Add(out[d+1], c)
+		out[d].Sub(out[d], new(big.Rat).Mul(c, root))
+	}
+	return out
+}
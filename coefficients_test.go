@@ -0,0 +1,58 @@
+package hashira
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPolynomialString(t *testing.T) {
+	tests := []struct {
+		coeffs []int64 // constant term first
+		want   string
+	}{
+		{nil, "0"},
+		{[]int64{0, 0}, "0"},
+		{[]int64{7}, "7"},
+		{[]int64{-7}, "-7"},
+		{[]int64{7, 0, 3}, "3x^2 + 7"},
+		{[]int64{7, -1, 3}, "3x^2 - x + 7"},
+		{[]int64{0, 1}, "x"},
+		{[]int64{-1, 0, 0, -1}, "-x^3 - 1"},
+		{[]int64{1, -12, 0, 0, 5}, "5x^4 - 12x + 1"},
+	}
+	for _, tt := range tests {
+		coeffs := make([]*big.Int, len(tt.coeffs))
+		for i, c := range tt.coeffs {
+			coeffs[i] = big.NewInt(c)
+		}
+		if got := PolynomialString(coeffs); got != tt.want {
+			t.Errorf("PolynomialString(%v) = %q, want %q", tt.coeffs, got, tt.want)
+		}
+	}
+}
+
+func TestRecoverCoefficientsRat(t *testing.T) {
+	// f(x) = x^2/2 + x/2 + 1 through (0, 1), (1, 2), (2, 4).
+	points := []Point{
+		{X: big.NewInt(0), Y: big.NewInt(1)},
+		{X: big.NewInt(1), Y: big.NewInt(2)},
+		{X: big.NewInt(2), Y: big.NewInt(4)},
+	}
+	got := RecoverCoefficientsRat(points)
+	want := []string{"1", "1/2", "1/2"}
+	if len(got) != len(want) {
+		t.Fatalf("RecoverCoefficientsRat returned %d coefficients, want %d", len(got), len(want))
+	}
+	for d, c := range got {
+		if c.RatString() != want[d] {
+			t.Errorf("coefficient of x^%d = %s, want %s", d, c.RatString(), want[d])
+		}
+	}
+
+	if _, err := RecoverCoefficients(points); err == nil {
+		t.Error("RecoverCoefficients with fractional coefficients: got nil error")
+	}
+	if RecoverCoefficientsRat(nil) != nil {
+		t.Error("RecoverCoefficientsRat(nil) is not nil")
+	}
+}
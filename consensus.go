@@ -0,0 +1,316 @@
+package hashira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// RecoverSecretConsensus recovers the secret from points when some of the
+// shares may be corrupt. It interpolates every combination of k points and
+// returns the secret that the most combinations agree on. Combinations whose
+// constant term is not an integer are ignored. It returns an error if no
+// combination yields an integer secret or if the leading candidates are tied.
+func RecoverSecretConsensus(points []Point, k int) (*big.Int, error) {
+	return RecoverSecretConsensusCtx(context.Background(), points, k)
+}
+
+// RecoverSecretConsensusCtx is like RecoverSecretConsensus but stops trying
+// combinations once ctx is done. In that case it returns ctx.Err() together
+// with the leading secret among the combinations tried so far, or a nil
+// secret if there is none.
+func RecoverSecretConsensusCtx(ctx context.Context, points []Point, k int) (*big.Int, error) {
+	secret, _, err := RecoverSecretConsensusDetailed(ctx, points, k)
+	return secret, err
+}
+
+// RecoverSecretConsensusDetailed is like RecoverSecretConsensusCtx but also
+// returns the points that agree with the secret: those lying on the
+// polynomial through the combination that produced it, in the order they
+// appear in points.
+func RecoverSecretConsensusDetailed(ctx context.Context, points []Point, k int) (secret *big.Int, used []Point, err error) {
+	best, err := consensusWorkers(ctx, points, k, runtime.NumCPU())
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if best == nil {
+		return nil, nil, err
+	}
+
+	subset := subsetOf(points, best.combo)
+	for _, p := range points {
+		if onPolynomial(subset, p) {
+			used = append(used, p)
+		}
+	}
+	return best.secret, used, err
+}
+
+// RecoverSecretConsensusMaxErrors recovers the secret from points of which
+// at most maxErrors may be corrupt, where maxErrors is between 0 and
+// (len(points)-k)/2. Any k+maxErrors points include k good ones, so only
+// the combinations of k among the first k+maxErrors points are tried, and
+// the search stops at the first whose polynomial has an integer constant
+// term and agrees with all but at most maxErrors of the points. That
+// polynomial is then the only one that does. Like
+// RecoverSecretConsensusDetailed it also returns the points that agree with
+// the secret. It returns an error if no combination is within the error
+// budget, and ctx.Err() if ctx is done first.
+func RecoverSecretConsensusMaxErrors(ctx context.Context, points []Point, k, maxErrors int) (secret *big.Int, used []Point, err error) {
+	if err := validatePoints(points); err != nil {
+		return nil, nil, err
+	}
+	if err := validateThreshold(k, len(points)); err != nil {
+		return nil, nil, err
+	}
+	if err := validateMaxErrors(maxErrors, k, len(points)); err != nil {
+		return nil, nil, err
+	}
+
+	progress, _ := ctx.Value(progressKey{}).(func(done, total int64))
+	total := binomial(k+maxErrors, k)
+	var done int64
+	forEachCombination(k+maxErrors, k, func(combo []int) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		if progress != nil {
+			done++
+			defer progress(done, total)
+		}
+		subset := subsetOf(points, combo)
+		s, sErr := RecoverSecret(subset)
+		if sErr != nil {
+			return true
+		}
+		var agree []Point
+		for _, p := range points {
+			if onPolynomial(subset, p) {
+				agree = append(agree, p)
+			}
+		}
+		if len(agree) < len(points)-maxErrors {
+			return true
+		}
+		secret, used = s, agree
+		return false
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret == nil {
+		return nil, nil, errTooManyErrors(maxErrors)
+	}
+	return secret, used, nil
+}
+
+// DetectOutliers returns the points that do not lie on the polynomial agreed
+// on by the most combinations of k points, as determined by
+// RecoverSecretConsensus. The result is in the same order as points and is
+// empty if every point is consistent.
+func DetectOutliers(points []Point, k int) ([]Point, error) {
+	best, err := consensus(points, k)
+	if err != nil {
+		return nil, err
+	}
+
+	return offPolynomial(subsetOf(points, best.combo), points), nil
+}
+
+// subsetOf returns the points at the indices in combo.
+func subsetOf(points []Point, combo []int) []Point {
+	subset := make([]Point, len(combo))
+	for i, idx := range combo {
+		subset[i] = points[idx]
+	}
+	return subset
+}
+
+// progressKey is the context key for the callback set by
+// WithConsensusProgress.
+type progressKey struct{}
+
+// WithConsensusProgress returns a copy of ctx that makes the consensus
+// functions given it call fn as combinations are evaluated, with the number
+// done so far and the total, which saturates at math.MaxInt64. fn is called
+// from a single goroutine after every combination, so it should be cheap and
+// do its own throttling.
+func WithConsensusProgress(ctx context.Context, fn func(done, total int64)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// binomial returns n choose k, saturating at math.MaxInt64.
+func binomial(n, k int) int64 {
+	b := new(big.Int).Binomial(int64(n), int64(k))
+	if !b.IsInt64() {
+		return math.MaxInt64
+	}
+	return b.Int64()
+}
+
+// consensus interpolates every combination of k points and returns the
+// candidate secret with the most votes.
+func consensus(points []Point, k int) (*candidate, error) {
+	return consensusWorkers(context.Background(), points, k, runtime.NumCPU())
+}
+
+// consensusWorkers is consensus with the combinations spread across the
+// given number of goroutines. If ctx is done, no further combinations are
+// started and the votes cast so far are counted. The workers share a
+// secretCache, so sub-combinations common to many combinations are
+// interpolated only once.
+func consensusWorkers(ctx context.Context, points []Point, k, workers int) (*candidate, error) {
+	if err := validatePoints(points); err != nil {
+		return nil, err
+	}
+	if err := validateThreshold(k, len(points)); err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	cache := newSecretCache(points)
+	combos := make(chan []int, workers)
+	results := make(chan vote, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for combo := range combos {
+				// Non-integer results are sent too, with a nil secret, so
+				// that progress counts every combination.
+				v := vote{combo: combo}
+				if secret := cache.secret(combo); secret.IsInt() {
+					v.secret = new(big.Int).Set(secret.Num())
+				}
+				results <- v
+			}
+		}()
+	}
+
+	go func() {
+		forEachCombination(len(points), k, func(combo []int) bool {
+			select {
+			case combos <- append([]int(nil), combo...):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		close(combos)
+		wg.Wait()
+		close(results)
+	}()
+
+	progress, _ := ctx.Value(progressKey{}).(func(done, total int64))
+	total := binomial(len(points), k)
+	var done int64
+
+	votes := make(map[string]*candidate)
+	for v := range results {
+		if progress != nil {
+			done++
+			progress(done, total)
+		}
+		if v.secret == nil {
+			continue
+		}
+		key := v.secret.String()
+		c, ok := votes[key]
+		if !ok {
+			c = &candidate{secret: v.secret, combo: v.combo}
+			votes[key] = c
+		} else if lessCombo(v.combo, c.combo) {
+			c.combo = v.combo
+		}
+		c.votes++
+	}
+
+	return pickConsensus(votes)
+}
+
+// vote is the secret interpolated from one combination of points, or nil if
+// it is not an integer.
+type vote struct {
+	secret *big.Int
+	combo  []int
+}
+
+// candidate is a secret proposed by one or more combinations of points.
+type candidate struct {
+	secret *big.Int
+	votes  int
+	// combo holds the indices of the lexicographically first combination
+	// that produced secret, so the choice does not depend on scheduling.
+	combo []int
+}
+
+// pickConsensus returns the candidate with the most votes.
+func pickConsensus(votes map[string]*candidate) (*candidate, error) {
+	var best *candidate
+	tied := false
+	for _, c := range votes {
+		switch {
+		case best == nil || c.votes > best.votes:
+			best, tied = c, false
+		case c.votes == best.votes:
+			tied = true
+		}
+	}
+	if best == nil {
+		return nil, errors.New("no combination of points yields an integer secret")
+	}
+	if tied {
+		return nil, fmt.Errorf("no consensus: several secrets are each supported by %d combinations", best.votes)
+	}
+	return best, nil
+}
+
+// forEachCombination calls fn with every k-element combination of the
+// indices 0..n-1 in lexicographic order, stopping early if fn returns false.
+// The slice passed to fn is reused between calls.
+func forEachCombination(n, k int, fn func(combo []int) bool) {
+	if k < 0 || k > n {
+		return
+	}
+	combo := make([]int, k)
+	for i := range combo {
+		combo[i] = i
+	}
+	for {
+		if !fn(combo) {
+			return
+		}
+
+		i := k - 1
+		for i >= 0 && combo[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		combo[i]++
+		for j := i + 1; j < k; j++ {
+			combo[j] = combo[j-1] + 1
+		}
+	}
+}
+
+// lessCombo reports whether combination a sorts before b lexicographically.
+func lessCombo(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package hashira
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// BenchmarkConsensus compares the sequential and parallel combination search
+// for n=20, k=7 (77520 combinations).
+func BenchmarkConsensus(b *testing.B) {
+	const n, k = 20, 7
+	points := make([]Point, n)
+	for i := 0; i < n; i++ {
+		x := big.NewInt(int64(i + 1))
+		// f(x) = x^6 + 3x^2 + 11
+		y := new(big.Int).Exp(x, big.NewInt(6), nil)
+		y.Add(y, new(big.Int).Mul(big.NewInt(3), new(big.Int).Mul(x, x)))
+		y.Add(y, big.NewInt(11))
+		points[i] = Point{X: x, Y: y}
+	}
+	points[4].Y.Add(points[4].Y, big.NewInt(1))
+
+	counts := []int{1}
+	if n := runtime.NumCPU(); n > 1 {
+		counts = append(counts, n)
+	}
+
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				best, err := consensusWorkers(context.Background(), points, k, workers)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if best.secret.Int64() != 11 {
+					b.Fatalf("secret = %s, want 11", best.secret)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSecretCache compares interpolating every combination for n=15,
+// k=6 (5005 combinations) from scratch with building each from cached
+// sub-combinations.
+func BenchmarkSecretCache(b *testing.B) {
+	const n, k = 15, 6
+	points := make([]Point, n)
+	for i := range points {
+		x := big.NewInt(int64(i + 1))
+		// f(x) = 2x^5 + x^3 + 7
+		y := new(big.Int).Exp(x, big.NewInt(5), nil)
+		y.Mul(y, big.NewInt(2))
+		y.Add(y, new(big.Int).Exp(x, big.NewInt(3), nil))
+		y.Add(y, big.NewInt(7))
+		points[i] = Point{X: x, Y: y}
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		subset := make([]Point, k)
+		for i := 0; i < b.N; i++ {
+			forEachCombination(n, k, func(combo []int) bool {
+				for j, idx := range combo {
+					subset[j] = points[idx]
+				}
+				secret, err := lagrangeInterpolateAtZero(subset)
+				if err != nil || secret.Int64() != 7 {
+					b.Fatalf("combination %v: secret %v, err %v", combo, secret, err)
+				}
+				return true
+			})
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cache := newSecretCache(points)
+			forEachCombination(n, k, func(combo []int) bool {
+				secret := cache.secret(combo)
+				if !secret.IsInt() || secret.Num().Int64() != 7 {
+					b.Fatalf("combination %v: secret %v", combo, secret)
+				}
+				return true
+			})
+		}
+	})
+}
+
+func TestRecoverSecretConsensusMaxErrors(t *testing.T) {
+	const n, k = 9, 4
+	points := make([]Point, n)
+	for i := range points {
+		// f(x) = 3x^3 - 2x + 5
+		points[i] = Point{X: big.NewInt(int64(i + 1)), Y: evalInt64([]int64{5, -2, 0, 3}, int64(i+1))}
+	}
+	points[0].Y.Add(points[0].Y, big.NewInt(1))
+	points[6].Y.Add(points[6].Y, big.NewInt(1))
+
+	secret, used, err := RecoverSecretConsensusMaxErrors(context.Background(), points, k, 2)
+	if err != nil || secret.Int64() != 5 || len(used) != n-2 {
+		t.Errorf("two errors, budget 2: secret %v with %d points agreeing, err %v; want 5 with %d", secret, len(used), err, n-2)
+	}
+
+	_, _, err = RecoverSecretConsensusMaxErrors(context.Background(), points, k, 1)
+	if err == nil || !strings.Contains(err.Error(), "more than 1 corrupted") {
+		t.Errorf("two errors, budget 1: err %v, want one about more than 1 corrupted share", err)
+	}
+
+	if _, _, err := RecoverSecretConsensusMaxErrors(context.Background(), points, k, 3); err == nil {
+		t.Errorf("budget 3 with n=%d, k=%d: want an out-of-range error", n, k)
+	}
+}
+
+// TestConsensusLargeK runs the consensus search at k=100 with one point to
+// spare, where building each combination from cached sub-combinations
+// would take far too long.
+func TestConsensusLargeK(t *testing.T) {
+	points, secret := largeKPoints(t, 100)
+	got, err := RecoverSecretConsensus(points, len(points)-1)
+	if err != nil || got.Cmp(secret) != 0 {
+		t.Errorf("RecoverSecretConsensus = %v, %v; want %s", got, err, secret)
+	}
+}
+
+// BenchmarkConsensusLargeK benchmarks the consensus search for n=101, k=100
+// (101 combinations).
+func BenchmarkConsensusLargeK(b *testing.B) {
+	points, _ := largeKPoints(b, 100)
+	for i := 0; i < b.N; i++ {
+		if _, err := RecoverSecretConsensus(points, len(points)-1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// largeKPoints returns k+1 points on a polynomial of degree k-1, together
+// with its constant term.
+func largeKPoints(tb testing.TB, k int) ([]Point, *big.Int) {
+	points, secret := pointsOnPolynomial(k, 1)
+	x := big.NewInt(int64(k + 1))
+	y, err := EvaluateAt(points, x)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return append(points, Point{X: x, Y: y}), secret
+}
+
+func TestDetectOutliers(t *testing.T) {
+	const n, k = 7, 3
+	tests := []struct {
+		name    string
+		corrupt []int // indices of the shares to corrupt
+	}{
+		{"consistent", nil},
+		{"one corrupt", []int{2}},
+		{"two corrupt", []int{0, 6}},
+		// With n-k-1 shares corrupt, the k+1 good ones still outvote every
+		// combination that includes a corrupt share.
+		{"n-k-1 corrupt", []int{0, 3, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := quadraticShares(n, tt.corrupt)
+			outliers, err := DetectOutliers(points, k)
+			if err != nil {
+				t.Fatalf("DetectOutliers: %v", err)
+			}
+			if len(outliers) != len(tt.corrupt) {
+				t.Fatalf("DetectOutliers = %s, want the shares at indices %v", PointsString(outliers), tt.corrupt)
+			}
+			for i, idx := range tt.corrupt {
+				if outliers[i].X.Cmp(points[idx].X) != 0 {
+					t.Errorf("outlier %d is at x=%s, want x=%s", i, outliers[i].X, points[idx].X)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectOutliersNoConsensus(t *testing.T) {
+	// With n-k shares corrupt only k are good, so the true polynomial is
+	// supported by a single combination, like those through corrupt shares.
+	if out, err := DetectOutliers(quadraticShares(6, []int{0, 2, 4}), 3); err == nil {
+		t.Errorf("n-k corrupt shares: DetectOutliers = %s, want a no-consensus error", PointsString(out))
+	}
+
+	// Two points on y = x and two on y = 10x - 20: every integer secret
+	// has one combination behind it.
+	tied := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(1)},
+		{X: big.NewInt(2), Y: big.NewInt(2)},
+		{X: big.NewInt(3), Y: big.NewInt(10)},
+		{X: big.NewInt(4), Y: big.NewInt(20)},
+	}
+	if _, err := DetectOutliers(tied, 2); err == nil || !strings.Contains(err.Error(), "no consensus") {
+		t.Errorf("tied candidates: err %v, want a no-consensus error", err)
+	}
+}
+
+// quadraticShares returns the shares at x = 1..n of f(x) = 2x^2 + 3x + 5,
+// with the y-coordinate at the j-th index in corrupt increased by j+1.
+func quadraticShares(n int, corrupt []int) []Point {
+	points := make([]Point, n)
+	for i := range points {
+		x := int64(i + 1)
+		points[i] = Point{X: big.NewInt(x), Y: big.NewInt(2*x*x + 3*x + 5)}
+	}
+	for j, idx := range corrupt {
+		points[idx].Y.Add(points[idx].Y, big.NewInt(int64(j+1)))
+	}
+	return points
+}
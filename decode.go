@@ -0,0 +1,239 @@
+package hashira
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// MaxBase is the largest base accepted by DecodeValue.
+const MaxBase = 62
+
+// digits is the alphabet for bases above 36: 0-9 have values 0-9, A-Z have
+// values 10-35 and a-z have values 36-61. A base b uses the first b digits,
+// so base 58 here is not the Bitcoin base58 alphabet.
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Errors wrapped by DecodeError to say which part of a share was malformed.
+// Test for them with errors.Is.
+var (
+	ErrInvalidKey   = errors.New("invalid key")
+	ErrInvalidBase  = errors.New("invalid base")
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// DecodeError records a share that could not be decoded.
+type DecodeError struct {
+	Key   string // x-coordinate key of the share, if known
+	Base  string // base as written in the input
+	Value string // value as written in the input
+	Err   error  // wraps ErrInvalidKey, ErrInvalidBase or ErrInvalidValue
+}
+
+func (e *DecodeError) Error() string {
+	if e.Key == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("decoding key '%s': %v", e.Key, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// FormatError reports a share file that is not well-formed: invalid JSON,
+// or a missing or malformed "keys", "n" or "k". Shares that cannot be
+// decoded are reported as *DecodeError instead.
+type FormatError struct {
+	Err error
+}
+
+func (e *FormatError) Error() string { return e.Err.Error() }
+
+func (e *FormatError) Unwrap() error { return e.Err }
+
+// radixPrefixes maps the lower-case letter of a 0x, 0o or 0b prefix to the
+// base it announces.
+var radixPrefixes = map[byte]int{'x': 16, 'o': 8, 'b': 2}
+
+// DecodeValue decodes value written in the given base, which must be between
+// 2 and MaxBase. Bases up to 36 are case-insensitive, as with
+// big.Int.SetString. Larger bases use the case-sensitive alphabet 0-9, A-Z,
+// a-z.
+//
+// The value may start with a '+' or '-' sign, followed by a 0x, 0o or 0b
+// prefix that matches base. A prefix that names a different base is an
+// error, unless its letter is an ordinary digit in base: "0b1" in base 16 is
+// the number 0xb1. Underscores and whitespace anywhere in value are ignored,
+// so digits may be grouped as in "1_000_000" or "ff ff ff". Errors are of
+// type *DecodeError.
+func DecodeValue(value string, base int) (*big.Int, error) {
+	fail := func(err error) (*big.Int, error) {
+		return nil, &DecodeError{Base: fmt.Sprint(base), Value: value, Err: err}
+	}
+
+	if base == 1 {
+		return fail(fmt.Errorf("%w 1: unary (tally) values are not supported; bases must be between 2 and %d", ErrInvalidBase, MaxBase))
+	}
+	if base < 2 || base > MaxBase {
+		return fail(fmt.Errorf("%w %d: must be between 2 and %d", ErrInvalidBase, base, MaxBase))
+	}
+
+	body, negative, err := splitValue(stripSeparators(value), base)
+	if err != nil {
+		return fail(err)
+	}
+
+	var n *big.Int
+	if base <= 36 {
+		var ok bool
+		if n, ok = new(big.Int).SetString(body, base); !ok || body[0] == '+' || body[0] == '-' {
+			return fail(fmt.Errorf("%w %q for base %d", ErrInvalidValue, value, base))
+		}
+	} else {
+		n = new(big.Int)
+		b := big.NewInt(int64(base))
+		for _, r := range body {
+			d := strings.IndexRune(digits[:base], r)
+			if d < 0 {
+				return fail(fmt.Errorf("%w %q for base %d: bad digit %q", ErrInvalidValue, value, base, r))
+			}
+			n.Mul(n, b).Add(n, big.NewInt(int64(d)))
+		}
+	}
+
+	if negative {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// DecodeDecimal decodes value like DecodeValue, but also accepts a fractional
+// part after a '.', so "1.5" in base 10 and "1.1" in base 2 are both 3/2.
+// Errors are of type *DecodeError.
+func DecodeDecimal(value string, base int) (*big.Rat, error) {
+	intPart, frac, found := strings.Cut(stripSeparators(value), ".")
+	if !found {
+		n, err := DecodeValue(value, base)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt(n), nil
+	}
+	if strings.Contains(frac, ".") {
+		return nil, &DecodeError{Base: fmt.Sprint(base), Value: value, Err: fmt.Errorf("%w %q: more than one '.'", ErrInvalidValue, value)}
+	}
+
+	digitsOnly := intPart + frac
+	if strings.TrimLeft(digitsOnly, "+-") == "" {
+		return nil, &DecodeError{Base: fmt.Sprint(base), Value: value, Err: fmt.Errorf("%w %q: no digits", ErrInvalidValue, value)}
+	}
+	n, err := DecodeValue(digitsOnly, base)
+	if err != nil {
+		var de *DecodeError
+		if errors.As(err, &de) {
+			de.Value = value
+		}
+		return nil, err
+	}
+
+	den := new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(len(frac))), nil)
+	return new(big.Rat).SetFrac(n, den), nil
+}
+
+// EncodedShare is a share as written in a share file: an x-coordinate and a
+// value in the given base.
+type EncodedShare struct {
+	X     string
+	Base  int
+	Value string
+}
+
+// PointFromEncoded decodes a share without going through JSON. x is written
+// in base 10, or after a 0x, 0o or 0b prefix, as a share file key. value is
+// decoded with DecodeValue. Errors are of type *DecodeError.
+func PointFromEncoded(x string, base int, value string) (Point, error) {
+	xVal, ok := parseKey(x)
+	if !ok {
+		return Point{}, &DecodeError{
+			Key:   x,
+			Base:  fmt.Sprint(base),
+			Value: value,
+			Err:   fmt.Errorf("%w: not an integer", ErrInvalidKey),
+		}
+	}
+
+	y, err := DecodeValue(value, base)
+	if err != nil {
+		var de *DecodeError
+		if errors.As(err, &de) {
+			de.Key = x
+		}
+		return Point{}, err
+	}
+	return Point{X: xVal, Y: y}, nil
+}
+
+// PointsFromEncoded decodes each of shares with PointFromEncoded, stopping at
+// the first that fails.
+func PointsFromEncoded(shares []EncodedShare) ([]Point, error) {
+	points := make([]Point, len(shares))
+	for i, s := range shares {
+		p, err := PointFromEncoded(s.X, s.Base, s.Value)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = p
+	}
+	return points, nil
+}
+
+// splitValue strips the sign and any radix prefix from value, returning the
+// remaining digits and whether the value is negative.
+func splitValue(value string, base int) (body string, negative bool, err error) {
+	body = value
+	if body != "" && (body[0] == '+' || body[0] == '-') {
+		negative = body[0] == '-'
+		body = body[1:]
+	}
+
+	if len(body) > 2 && body[0] == '0' {
+		letter := body[1]
+		prefixBase, isPrefix := radixPrefixes[letter|0x20]
+		switch {
+		case !isPrefix:
+		case prefixBase == base:
+			body = body[2:]
+		case !isDigit(letter, base):
+			return "", false, fmt.Errorf("%w %q: prefix 0%c contradicts base %d", ErrInvalidValue, value, letter, base)
+		}
+	}
+
+	if body == "" {
+		return "", false, fmt.Errorf("%w %q: no digits", ErrInvalidValue, value)
+	}
+	return body, negative, nil
+}
+
+// stripSeparators returns value without the underscores and whitespace used
+// to group digits.
+func stripSeparators(value string) string {
+	isSeparator := func(r rune) bool { return r == '_' || unicode.IsSpace(r) }
+	if !strings.ContainsFunc(value, isSeparator) {
+		return value
+	}
+	return strings.Map(func(r rune) rune {
+		if isSeparator(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// isDigit reports whether c is a valid digit in base.
+func isDigit(c byte, base int) bool {
+	if base <= 36 && c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	return strings.IndexByte(digits[:base], c) >= 0
+}
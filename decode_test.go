@@ -0,0 +1,111 @@
+package hashira
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeValueSeparators(t *testing.T) {
+	tests := []struct {
+		value string
+		base  int
+		want  string
+	}{
+		{"1_000_000", 10, "1000000"},
+		{"1 000 000", 10, "1000000"},
+		{" -42 ", 10, "-42"},
+		{"ff ff", 16, "65535"},
+		{"0x_ff_ff", 16, "65535"},
+		{"1010_1010", 2, "170"},
+		{"zZ_zZ", 62, "14676365"},
+	}
+	for _, tt := range tests {
+		got, err := DecodeValue(tt.value, tt.base)
+		if err != nil {
+			t.Errorf("DecodeValue(%q, %d): %v", tt.value, tt.base, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("DecodeValue(%q, %d) = %s, want %s", tt.value, tt.base, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"_", " ", "__ _", "-_"} {
+		if _, err := DecodeValue(value, 10); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("DecodeValue(%q, 10) = %v, want ErrInvalidValue", value, err)
+		}
+	}
+
+	r, err := DecodeDecimal("1_000.2_5", 10)
+	if err != nil || r.RatString() != "4001/4" {
+		t.Errorf("DecodeDecimal(\"1_000.2_5\", 10) = %v, %v, want 4001/4", r, err)
+	}
+}
+
+func TestDecodeValueBadBase(t *testing.T) {
+	for _, base := range []int{-2, 0, 1, MaxBase + 1} {
+		if _, err := DecodeValue("1", base); !errors.Is(err, ErrInvalidBase) {
+			t.Errorf("DecodeValue(\"1\", %d) = %v, want ErrInvalidBase", base, err)
+		}
+	}
+	if _, err := DecodeValue("111", 1); err == nil || !strings.Contains(err.Error(), "unary") {
+		t.Errorf("DecodeValue(\"111\", 1) = %v, want an error about unary values", err)
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		value string
+		base  int
+		want  string // "" if the value is invalid
+	}{
+		// Above base 36 the alphabet is case-sensitive: 0-9, A-Z, a-z.
+		{"z", 62, "61"},
+		{"Z", 62, "35"},
+		{"a", 62, "36"},
+		{"10", 62, "62"},
+		{"Zz", 37, ""},
+		{"a", 37, "36"},
+		{"b", 37, ""},
+		// Up to base 36 letters are case-insensitive.
+		{"a", 36, "10"},
+		{"Z", 36, "35"},
+		{"a", 10, ""},
+		{"2", 2, ""},
+
+		{"+5", 10, "5"},
+		{"-5", 10, "-5"},
+		{"-z", 62, "-61"},
+		{"+-5", 10, ""},
+		{"--5", 10, ""},
+
+		{"0x1f", 16, "31"},
+		{"0X1F", 16, "31"},
+		{"-0x1f", 16, "-31"},
+		{"0o17", 8, "15"},
+		{"0b101", 2, "5"},
+		{"0x1", 8, ""},
+		{"0o7", 16, ""},
+		// A prefix letter that is a digit in the base is read as one.
+		{"0b1", 16, "177"},
+		{"0o1", 36, "865"},
+
+		{"-", 10, ""},
+		{"+", 10, ""},
+		{"0x", 16, ""},
+		{"", 10, ""},
+	}
+	for _, tt := range tests {
+		got, err := DecodeValue(tt.value, tt.base)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Errorf("DecodeValue(%q, %d) = %v, %v; want ErrInvalidValue", tt.value, tt.base, got, err)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("DecodeValue(%q, %d) = %v, %v; want %s", tt.value, tt.base, got, err, tt.want)
+		}
+	}
+}
@@ -1,126 +1,235 @@
-package main
+// Package hashira reconstructs secrets from Shamir secret shares using
+// Lagrange interpolation.
+package hashira
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
-	"os"
-	"sort"
-	"strconv"
 )
 
-type Point struct {
-	X *big.Int
-	Y *big.Int
+// NonIntegerError reports that an interpolated value, which the caller
+// needed to be an integer, is a fraction. This usually means the points do
+// not lie on a polynomial with integer coefficients.
+type NonIntegerError struct {
+	What  string   // what was being computed, such as "secret"
+	Value *big.Rat // the exact fractional result
 }
 
-func lagrangeInterpolateAtZero(points []Point) *big.Int {
-	secret := new(big.Rat).SetInt64(0)
-	k := len(points)
+func (e *NonIntegerError) Error() string {
+	return fmt.Sprintf("computed %s is non-integer: %s", e.What, e.Value.RatString())
+}
 
-	xRats := make([]*big.Rat, k)
-	yRats := make([]*big.Rat, k)
-	for i := 0; i < k; i++ {
-		xRats[i] = new(big.Rat).SetInt(points[i].X)
-		yRats[i] = new(big.Rat).SetInt(points[i].Y)
+// RecoverSecret returns the constant term f(0) of the unique polynomial of
+// degree len(points)-1 passing through points. With a single point the
+// polynomial is constant and the secret is its y. A point at x=0 is the
+// secret itself and is returned as is. It returns an error if points
+// is empty, if two points share an x-coordinate or if the constant term is
+// not an integer.
+func RecoverSecret(points []Point) (*big.Int, error) {
+	if err := validatePoints(points); err != nil {
+		return nil, err
 	}
+	return lagrangeInterpolateAtZero(points)
+}
 
-	for i := 0; i < k; i++ {
-		numerator := new(big.Rat).SetInt64(1)
-		denominator := new(big.Rat).SetInt64(1)
-
-		for j := 0; j < k; j++ {
-			if i == j {
-				continue
-			}
-			numerator.Mul(numerator, xRats[j])
-			diff := new(big.Rat).Sub(xRats[i], xRats[j])
-			denominator.Mul(denominator, diff)
-		}
-
-		lagrangeBasis := new(big.Rat).Quo(numerator, denominator)
-		term := new(big.Rat).Mul(yRats[i], lagrangeBasis)
-		secret.Add(secret, term)
+// RecoverSecretDetailed recovers the secret from the first k points, like
+// RecoverSecret, and also returns the points it used. See
+// RecoverSecretConsensusDetailed for the equivalent when shares may be
+// corrupt.
+func RecoverSecretDetailed(points []Point, k int) (secret *big.Int, used []Point, err error) {
+	if err := validateThreshold(k, len(points)); err != nil {
+		return nil, nil, err
 	}
-
-	if !secret.IsInt() {
-		log.Fatal("Error: The calculated secret is not an integer. Check the input points.")
+	used = points[:k:k]
+	secret, err = RecoverSecret(used)
+	if err != nil {
+		return nil, nil, err
 	}
+	return secret, used, nil
+}
 
-	return secret.Num()
+// RecoverSecretWithDegree recovers the secret from the first k points, like
+// RecoverSecretDetailed, and also returns the degree of the polynomial
+// interpolated, k-1. Comparing it with InferDegree of the same points shows
+// whether k was larger than the polynomial needs.
+func RecoverSecretWithDegree(points []Point, k int) (secret *big.Int, degree int, err error) {
+	secret, _, err = RecoverSecretDetailed(points, k)
+	if err != nil {
+		return nil, 0, err
+	}
+	return secret, k - 1, nil
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		log.Fatalf("Usage: go run main.go <path_to_json_file>")
+// RecoverSecretFromSlices is RecoverSecret for points given as parallel
+// slices of x- and y-coordinates, so that xs[i] and ys[i] form one point. It
+// returns an error if the slices are empty or differ in length.
+func RecoverSecretFromSlices(xs, ys []*big.Int) (*big.Int, error) {
+	if len(xs) != len(ys) {
+		return nil, fmt.Errorf("got %d x-coordinates but %d y-coordinates", len(xs), len(ys))
 	}
-	filePath := os.Args[1]
+	if len(xs) == 0 {
+		return nil, errors.New("no points given")
+	}
+	points := make([]Point, len(xs))
+	for i := range xs {
+		points[i] = Point{X: xs[i], Y: ys[i]}
+	}
+	return RecoverSecret(points)
+}
 
-	file, err := os.ReadFile(filePath)
-	if err != nil {
-		log.Fatalf("Error reading file: %v", err)
+// RecoverSecretRat returns the constant term f(0) of the unique polynomial
+// of degree len(points)-1 passing through points as an exact fraction, for
+// when the points may not lie on a polynomial with integer coefficients. It
+// returns nil if points is empty or two points share an x-coordinate.
+func RecoverSecretRat(points []Point) *big.Rat {
+	if validatePoints(points) != nil {
+		return nil
 	}
+	return lagrangeInterpolateAt(points, big.NewInt(0))
+}
 
-	var rawData map[string]json.RawMessage
-	if err := json.Unmarshal(file, &rawData); err != nil {
-		log.Fatalf("Error parsing JSON: %v", err)
+// EvaluateAt returns f(x) for the unique polynomial f of degree
+// len(points)-1 passing through points. It returns an error if f(x) is not an
+// integer. The computation uses only the differences x - x_i and x_i - x_j,
+// so x-coordinates that are large but close together cost no more than small
+// ones, and shifting them all by the same amount first would gain nothing.
+func EvaluateAt(points []Point, x *big.Int) (*big.Int, error) {
+	if err := validatePoints(points); err != nil {
+		return nil, err
 	}
 
-	var keys struct {
-		K int `json:"k"`
+	value := lagrangeInterpolateAt(points, x)
+	if !value.IsInt() {
+		return nil, &NonIntegerError{What: fmt.Sprintf("value at x=%s", x), Value: value}
 	}
-	if err := json.Unmarshal(rawData["keys"], &keys); err != nil {
-		log.Fatalf("Error parsing 'keys' object: %v", err)
+
+	return new(big.Int).Set(value.Num()), nil
+}
+
+// lagrangeInterpolateAtZero returns the secret f(0) through points, or a
+// *NonIntegerError if it is a fraction.
+func lagrangeInterpolateAtZero(points []Point) (*big.Int, error) {
+	num, den := lagrangeFracAtZero(points)
+	secret, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, &NonIntegerError{What: "secret", Value: new(big.Rat).SetFrac(num, den)}
 	}
-	k := keys.K
+	return secret, nil
+}
 
-	var points []Point
-	for key, rawValue := range rawData {
-		if key == "keys" {
-			continue
+// lagrangeFracAtZero computes the same sum as lagrangeInterpolateAt at x=0,
+// but as a single fraction num/den with den > 0, using big.Int arithmetic
+// throughout. The sum is kept over the least common multiple of the term
+// denominators seen so far rather than normalised after every addition as
+// big.Rat would, which avoids most of the allocations and GCD computations.
+func lagrangeFracAtZero(points []Point) (num, den *big.Int) {
+	// The numerator of L_i(0) is the product of -x_j over j != i, which is
+	// total / -x_i with total the product over every j. If some x_m is 0,
+	// L_m(0) is 1 and every other L_i(0) is 0.
+	total := big.NewInt(1)
+	for _, p := range points {
+		if p.X.Sign() == 0 {
+			return new(big.Int).Set(p.Y), big.NewInt(1)
 		}
+		total.Mul(total, p.X)
+	}
+	if len(points)%2 == 1 {
+		total.Neg(total)
+	}
 
-		xVal, err := strconv.ParseInt(key, 10, 64)
-		if err != nil {
-			log.Printf("Warning: could not parse key '%s' as an integer. Skipping.", key)
+	num, den = new(big.Int), big.NewInt(1)
+	termNum, termDen := new(big.Int), new(big.Int)
+	diff, gcd, t := new(big.Int), new(big.Int), new(big.Int)
+	for i, p := range points {
+		// termNum/termDen = y_i * L_i(0), whose denominator is the product
+		// of x_i - x_j.
+		termNum.Quo(total, p.X).Neg(termNum).Mul(termNum, p.Y)
+		if termNum.Sign() == 0 {
 			continue
 		}
-
-		var val struct {
-			Base  string `json:"base"`
-			Value string `json:"value"`
-		}
-		if err := json.Unmarshal(rawValue, &val); err != nil {
-			log.Fatalf("Error parsing point data for key '%s': %v", key, err)
-		}
-
-		base, err := strconv.Atoi(val.Base)
-		if err != nil {
-			log.Fatalf("Error converting base '%s' to integer: %v", val.Base, err)
+		termDen.SetInt64(1)
+		for j, q := range points {
+			if i != j {
+				termDen.Mul(termDen, diff.Sub(p.X, q.X))
+			}
 		}
-
-		yVal, success := new(big.Int).SetString(val.Value, base)
-		if !success {
-			log.Fatalf("Error decoding value '%s' with base %d", val.Value, base)
+		if termDen.Sign() < 0 {
+			termNum.Neg(termNum)
+			termDen.Neg(termDen)
 		}
 
-		points = append(points, Point{X: big.NewInt(xVal), Y: yVal})
+		// num/den += termNum/termDen over lcm(den, termDen).
+		gcd.GCD(nil, nil, den, termDen)
+		t.Quo(termDen, gcd)
+		num.Mul(num, t)
+		den.Mul(den, t)
+		t.Quo(den, termDen)
+		num.Add(num, t.Mul(t, termNum))
 	}
+	return num, den
+}
 
-	sort.Slice(points, func(i, j int) bool {
-		return points[i].X.Cmp(points[j].X) < 0
-	})
+// LagrangeBasisAtZero returns the Lagrange basis values L_i(0) for points,
+// the weights with which each y_i contributes to the secret:
+//
+//	secret = sum over i of y_i * L_i(0)
+//
+// They depend only on the x-coordinates. It returns an error if points is
+// empty or two points share an x-coordinate.
+func LagrangeBasisAtZero(points []Point) ([]*big.Rat, error) {
+	if err := validatePoints(points); err != nil {
+		return nil, err
+	}
+	return lagrangeBasisAt(points, big.NewInt(0)), nil
+}
 
-	if len(points) < k {
-		log.Fatalf("Error: Not enough points in JSON (%d) to meet requirement k=%d", len(points), k)
+// lagrangeInterpolateAt computes the sum of y_i * L_i(x).
+func lagrangeInterpolateAt(points []Point, x *big.Int) *big.Rat {
+	result := new(big.Rat)
+	for i, basis := range lagrangeBasisAt(points, x) {
+		if basis.Sign() == 0 {
+			continue
+		}
+		term := new(big.Rat).Mul(new(big.Rat).SetInt(points[i].Y), basis)
+		result.Add(result, term)
 	}
-	pointsToUse := points[:k]
+	return result
+}
 
-	secret := lagrangeInterpolateAtZero(pointsToUse)
+// lagrangeBasisAt returns L_i(x) = product over j != i of
+// (x - x_j) / (x_i - x_j) for each point.
+//
+// The numerators share all but one factor, so the full product of (x - x_j)
+// is computed once and each numerator is obtained by dividing out its own
+// factor. That is only valid while every factor is nonzero; if x equals some
+// x_m then L_m(x) is 1 and every other L_i(x) is 0.
+func lagrangeBasisAt(points []Point, x *big.Int) []*big.Rat {
+	k := len(points)
+	basis := make([]*big.Rat, k)
+
+	factors := make([]*big.Int, k)
+	total := big.NewInt(1)
+	for j := 0; j < k; j++ {
+		factors[j] = new(big.Int).Sub(x, points[j].X)
+		if factors[j].Sign() == 0 {
+			for i := range basis {
+				basis[i] = new(big.Rat)
+			}
+			basis[j].SetInt64(1)
+			return basis
+		}
+		total.Mul(total, factors[j])
+	}
 
-	fmt.Println("Successfully decoded points and calculated the secret.")
-	fmt.Println("-----------------------------------------------------")
-	fmt.Printf("Secret (C): %s\n", secret.String())
-	fmt.Println("-----------------------------------------------------")
+	for i := 0; i < k; i++ {
+		denominator := big.NewInt(1)
+		for j := 0; j < k; j++ {
+			if i != j {
+				denominator.Mul(denominator, new(big.Int).Sub(points[i].X, points[j].X))
+			}
+		}
+		basis[i] = new(big.Rat).SetFrac(new(big.Int).Quo(total, factors[i]), denominator)
+	}
+	return basis
 }
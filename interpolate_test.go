@@ -0,0 +1,527 @@
+package hashira
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRecoverSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string // decimal secret; empty if an error is expected
+		wantErr string
+	}{
+		{
+			name: "mixed small bases",
+			input: `{
+				"keys": {"n": 4, "k": 3},
+				"1": {"base": "10", "value": "4"},
+				"2": {"base": "2", "value": "111"},
+				"3": {"base": "10", "value": "12"},
+				"6": {"base": "4", "value": "213"}
+			}`,
+			want: "3",
+		},
+		{
+			name: "large base-16 values",
+			// f(x) = 0xdeadbeefcafebabe1234567890abcdef + 0x1f2e3d4c5b6a79881726354453627180 x
+			//        + 0x0badf00d0badf00d0badf00d x^2 + 7 x^3
+			// The Lagrange weights for these x are fractions but the sum is
+			// an integer.
+			input: `{
+				"keys": {"n": 4, "k": 4},
+				"2": {"base": "16", "value": "11d0a3988b08b6e026f3881356628715b"},
+				"5": {"base": "16", "value": "17a94f16eb80f8baca9efd21455947a1f"},
+				"9": {"base": "16", "value": "1f74de6a2b3c5f4a794952a00312ad37b"},
+				"14": {"base": "16", "value": "293351925bbfd2a2c47770a32113a17eb"}
+			}`,
+			want: "295990755076957304699390954000840642031",
+		},
+		{
+			name: "ten shares with k=7",
+			input: `{
+				"keys": {"n": 10, "k": 7},
+				"1": {"base": "6", "value": "13444211440455345511"},
+				"2": {"base": "15", "value": "aed7015a346d635"},
+				"3": {"base": "15", "value": "6aeeb69631c227c"},
+				"4": {"base": "16", "value": "e1b5e05623d881f"},
+				"5": {"base": "8", "value": "316034514573652620673"},
+				"6": {"base": "3", "value": "2122212201122002221120200210011020220200"},
+				"7": {"base": "3", "value": "20120221122211000100210021102001201112121"},
+				"8": {"base": "6", "value": "20220554335330240002224253"},
+				"9": {"base": "12", "value": "45153788322a1255483"},
+				"10": {"base": "7", "value": "1101613130313526312514143"}
+			}`,
+			want: "-6290016743746469796",
+		},
+		{
+			name: "non-integer secret",
+			// The line through (1, 1) and (3, 2) meets the y-axis at 1/2.
+			input: `{
+				"keys": {"n": 2, "k": 2},
+				"1": {"base": "10", "value": "1"},
+				"3": {"base": "10", "value": "2"}
+			}`,
+			wantErr: "computed secret is non-integer: 1/2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, k, err := ParseInput([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseInput: %v", err)
+			}
+			sort.Slice(points, func(i, j int) bool {
+				return points[i].X.Cmp(points[j].X) < 0
+			})
+
+			secret, err := RecoverSecret(points[:k])
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("RecoverSecret error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecoverSecret: %v", err)
+			}
+			if secret.String() != tt.want {
+				t.Errorf("RecoverSecret = %s, want %s", secret, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoverSecretNegativeX(t *testing.T) {
+	// f(x) = 2x^2 - 3x + 7
+	f := func(x int64) int64 { return 2*x*x - 3*x + 7 }
+
+	tests := []struct {
+		name string
+		xs   []int64
+	}{
+		{"all negative", []int64{-1, -2, -3}},
+		{"mixed", []int64{-2, 1, 3}},
+		{"mixed unsorted", []int64{4, -1, -5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := make([]Point, len(tt.xs))
+			for i, x := range tt.xs {
+				points[i] = Point{X: big.NewInt(x), Y: big.NewInt(f(x))}
+			}
+
+			secret, err := RecoverSecret(points)
+			if err != nil {
+				t.Fatalf("RecoverSecret: %v", err)
+			}
+			if secret.Int64() != 7 {
+				t.Errorf("RecoverSecret = %s, want 7", secret)
+			}
+
+			got, err := EvaluateAt(points, big.NewInt(-4))
+			if err != nil {
+				t.Fatalf("EvaluateAt: %v", err)
+			}
+			if got.Int64() != f(-4) {
+				t.Errorf("EvaluateAt(-4) = %s, want %d", got, f(-4))
+			}
+
+			prime := big.NewInt(101)
+			modSecret, err := RecoverSecretMod(points, prime)
+			if err != nil {
+				t.Fatalf("RecoverSecretMod: %v", err)
+			}
+			if modSecret.Int64() != 7 {
+				t.Errorf("RecoverSecretMod = %s, want 7", modSecret)
+			}
+		})
+	}
+}
+
+// TestRecoverSecretSmallK covers the smallest thresholds. With k=1 the
+// polynomial is the constant y_1, so the Lagrange sum has one term with an
+// empty product for its basis. A share at x=0 holds the secret itself; the
+// basis short-circuit returns its y without dividing by zero.
+func TestRecoverSecretSmallK(t *testing.T) {
+	tests := []struct {
+		name   string
+		points [][2]int64
+		want   int64
+	}{
+		{"k=1", [][2]int64{{5, 42}}, 42},
+		{"k=1 at x=0", [][2]int64{{0, 42}}, 42},
+		{"k=1 negative x", [][2]int64{{-3, -8}}, -8},
+		{"k=2", [][2]int64{{1, 3}, {2, 5}}, 1},
+		{"k=2 with x=0", [][2]int64{{4, 1}, {0, 9}}, 9},
+		{"k=2 negative slope", [][2]int64{{-1, 10}, {2, 1}}, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := make([]Point, len(tt.points))
+			for i, p := range tt.points {
+				points[i] = Point{X: big.NewInt(p[0]), Y: big.NewInt(p[1])}
+			}
+
+			secret, err := RecoverSecret(points)
+			if err != nil {
+				t.Fatalf("RecoverSecret: %v", err)
+			}
+			if secret.Int64() != tt.want {
+				t.Errorf("RecoverSecret = %s, want %d", secret, tt.want)
+			}
+
+			basis, err := LagrangeBasisAtZero(points)
+			if err != nil {
+				t.Fatalf("LagrangeBasisAtZero: %v", err)
+			}
+			sum := new(big.Rat)
+			for _, b := range basis {
+				sum.Add(sum, b)
+			}
+			if sum.Cmp(big.NewRat(1, 1)) != 0 {
+				t.Errorf("basis values %v sum to %s, want 1", basis, sum.RatString())
+			}
+		})
+	}
+}
+
+// largePoints returns n points on a polynomial of degree k-1 whose
+// coefficients are random 512-bit integers, with their y-coordinates encoded
+// in base, together with the secret.
+func largePoints(tb testing.TB, n, k, base int) ([]Point, *big.Int) {
+	tb.Helper()
+	rng := rand.New(rand.NewSource(512))
+	limit := new(big.Int).Lsh(big.NewInt(1), 512)
+	coeffs := make([]*big.Int, k)
+	for d := range coeffs {
+		coeffs[d] = new(big.Int).Rand(rng, limit)
+	}
+
+	shares := make([]EncodedShare, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		y := new(big.Int)
+		for d := k - 1; d >= 0; d-- {
+			y.Mul(y, x).Add(y, coeffs[d])
+		}
+		shares[i] = EncodedShare{X: x.String(), Base: base, Value: y.Text(base)}
+	}
+
+	points, err := PointsFromEncoded(shares)
+	if err != nil {
+		tb.Fatalf("PointsFromEncoded: %v", err)
+	}
+	return points, coeffs[0]
+}
+
+func TestRecoverSecretFromSlices(t *testing.T) {
+	xs := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	ys := []*big.Int{big.NewInt(4), big.NewInt(7), big.NewInt(12)}
+	secret, err := RecoverSecretFromSlices(xs, ys)
+	if err != nil {
+		t.Fatalf("RecoverSecretFromSlices: %v", err)
+	}
+	if secret.Int64() != 3 {
+		t.Errorf("RecoverSecretFromSlices = %s, want 3", secret)
+	}
+
+	if _, err := RecoverSecretFromSlices(xs, ys[:2]); err == nil {
+		t.Error("RecoverSecretFromSlices with mismatched lengths: got nil error")
+	}
+	if _, err := RecoverSecretFromSlices(nil, nil); err == nil {
+		t.Error("RecoverSecretFromSlices with no points: got nil error")
+	}
+}
+
+func TestRecoverSecretWithDegree(t *testing.T) {
+	// f(x) = x^2 + 3, given at four points so k=4 over-specifies it.
+	points := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(4)},
+		{X: big.NewInt(2), Y: big.NewInt(7)},
+		{X: big.NewInt(3), Y: big.NewInt(12)},
+		{X: big.NewInt(4), Y: big.NewInt(19)},
+	}
+	secret, degree, err := RecoverSecretWithDegree(points, 4)
+	if err != nil || secret.Int64() != 3 || degree != 3 {
+		t.Errorf("RecoverSecretWithDegree(k=4) = %v, %d, %v; want 3, 3, nil", secret, degree, err)
+	}
+	if inferred, err := InferDegree(points); err != nil || inferred != 2 {
+		t.Errorf("InferDegree = %d, %v; want 2", inferred, err)
+	}
+
+	if _, _, err := RecoverSecretWithDegree(points, 5); err == nil {
+		t.Error("RecoverSecretWithDegree with k above the number of points: got nil error")
+	}
+}
+
+func TestNilCoordinates(t *testing.T) {
+	one := big.NewInt(1)
+	tests := []struct {
+		name string
+		fn   func([]Point) error
+	}{
+		{"RecoverSecret", func(p []Point) error { _, err := RecoverSecret(p); return err }},
+		{"EvaluateAt", func(p []Point) error { _, err := EvaluateAt(p, one); return err }},
+		{"RecoverCoefficients", func(p []Point) error { _, err := RecoverCoefficients(p); return err }},
+		{"RecoverSecretMod", func(p []Point) error { _, err := RecoverSecretMod(p, big.NewInt(101)); return err }},
+		{"RecoverSecretConsensus", func(p []Point) error { _, err := RecoverSecretConsensus(p, 1); return err }},
+	}
+	for _, points := range [][]Point{
+		{{X: one, Y: one}, {X: nil, Y: one}},
+		{{X: one, Y: one}, {X: big.NewInt(2), Y: nil}},
+	} {
+		for _, tt := range tests {
+			err := tt.fn(points)
+			if err == nil || !strings.Contains(err.Error(), "point 1") {
+				t.Errorf("%s(%v) = %v, want an error naming point 1", tt.name, points, err)
+			}
+		}
+	}
+}
+
+// TestRecoverSecretRatSign checks that fractional secrets come back reduced
+// with a positive denominator, whatever the signs involved.
+func TestRecoverSecretRatSign(t *testing.T) {
+	tests := []struct {
+		points [][2]int64
+		want   string
+	}{
+		{[][2]int64{{1, 1}, {3, 2}}, "1/2"},
+		{[][2]int64{{1, -1}, {3, -2}}, "-1/2"},
+		{[][2]int64{{-1, 2}, {1, 1}}, "3/2"},
+		{[][2]int64{{2, 2}, {6, 4}}, "1"},
+		{[][2]int64{{1, 4}, {2, 7}, {3, 12}}, "3"},
+		{[][2]int64{{1, 2}, {3, 0}, {5, 0}}, "15/4"},
+	}
+	for _, tt := range tests {
+		points := make([]Point, len(tt.points))
+		for i, p := range tt.points {
+			points[i] = Point{X: big.NewInt(p[0]), Y: big.NewInt(p[1])}
+		}
+		got := RecoverSecretRat(points)
+		if got.RatString() != tt.want || got.Denom().Sign() <= 0 {
+			t.Errorf("RecoverSecretRat(%s) = %s (denominator %s), want %s", PointsString(points), got.RatString(), got.Denom(), tt.want)
+		}
+
+		_, err := RecoverSecret(points)
+		var nonInteger *NonIntegerError
+		if isInt := got.IsInt(); isInt != (err == nil) || (!isInt && (!errors.As(err, &nonInteger) || nonInteger.Value.Cmp(got) != 0)) {
+			t.Errorf("RecoverSecret(%s): error %v does not match RecoverSecretRat = %s", PointsString(points), err, got.RatString())
+		}
+	}
+}
+
+func TestRecoverSecretLargeValues(t *testing.T) {
+	const n, k = 9, 6
+	for _, base := range []int{2, 16} {
+		t.Run(fmt.Sprintf("base=%d", base), func(t *testing.T) {
+			points, want := largePoints(t, n, k, base)
+
+			got, err := RecoverSecret(points[:k])
+			if err != nil {
+				t.Fatalf("RecoverSecret: %v", err)
+			}
+			if got.Cmp(want) != 0 {
+				t.Errorf("RecoverSecret = %s, want %s", got, want)
+			}
+
+			points[2].Y.Add(points[2].Y, big.NewInt(1))
+			got, err = RecoverSecretConsensus(points, k)
+			if err != nil {
+				t.Fatalf("RecoverSecretConsensus: %v", err)
+			}
+			if got.Cmp(want) != 0 {
+				t.Errorf("RecoverSecretConsensus = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// BenchmarkConsensusLarge runs the consensus search over 512-bit shares for
+// n=15, k=6, for comparison with the small values of BenchmarkSecretCache.
+func BenchmarkConsensusLarge(b *testing.B) {
+	points, want := largePoints(b, 15, 6, 16)
+	for i := 0; i < b.N; i++ {
+		got, err := RecoverSecretConsensus(points, 6)
+		if err != nil || got.Cmp(want) != 0 {
+			b.Fatalf("RecoverSecretConsensus = %v, %v", got, err)
+		}
+	}
+}
+
+func BenchmarkInterpolate(b *testing.B) {
+	for _, k := range []int{5, 10, 50, 100} {
+		points, secret := pointsOnPolynomial(k, 1)
+		b.Run(fmt.Sprintf("k=%d", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				got, err := RecoverSecret(points)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if got.Cmp(secret) != 0 {
+					b.Fatalf("RecoverSecret = %s, want %s", got, secret)
+				}
+			}
+		})
+	}
+}
+
+// pointsOnPolynomial returns k points at x = 1..k on a polynomial of degree
+// k-1 with random 64-bit coefficients, together with its constant term. The
+// same k and seed always produce the same points.
+func pointsOnPolynomial(k int, seed int64) ([]Point, *big.Int) {
+	rng := rand.New(rand.NewSource(seed))
+	coeffs := make([]*big.Int, k)
+	for d := range coeffs {
+		coeffs[d] = new(big.Int).SetUint64(rng.Uint64())
+	}
+
+	points := make([]Point, k)
+	for i := range points {
+		x := big.NewInt(int64(i + 1))
+		y := new(big.Int)
+		for d := k - 1; d >= 0; d-- {
+			y.Mul(y, x).Add(y, coeffs[d])
+		}
+		points[i] = Point{X: x, Y: y}
+	}
+	return points, coeffs[0]
+}
+
+// BenchmarkEvaluateAtLargeX evaluates a polynomial of degree 9 from points
+// at x around 10^18 and from the same points shifted down to x around 0,
+// at a correspondingly shifted x. The Lagrange terms depend only on
+// differences of x-coordinates, so both do the same arithmetic.
+func BenchmarkEvaluateAtLargeX(b *testing.B) {
+	const k = 10
+	base := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	near, _ := pointsOnPolynomial(k, 1)
+	far := make([]Point, k)
+	for i, p := range near {
+		far[i] = Point{X: new(big.Int).Add(p.X, base), Y: p.Y}
+	}
+	x := big.NewInt(k + 1)
+	farX := new(big.Int).Add(x, base)
+
+	b.Run("near", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := EvaluateAt(near, x); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("far", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := EvaluateAt(far, farX); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkLagrangeNumerator compares lagrangeInterpolateAt, which divides
+// each numerator out of a cached product, with naiveLagrangeAt, which
+// recomputes every numerator from scratch.
+func BenchmarkLagrangeNumerator(b *testing.B) {
+	points, _ := pointsOnPolynomial(100, 1)
+	x := big.NewInt(0)
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lagrangeInterpolateAt(points, x)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveLagrangeAt(points, x)
+		}
+	})
+}
+
+func TestLagrangeInterpolateAtMatchesNaive(t *testing.T) {
+	points, _ := pointsOnPolynomial(12, 2)
+	for _, x := range []int64{0, -3, 5, 13} {
+		got := lagrangeInterpolateAt(points, big.NewInt(x))
+		want := naiveLagrangeAt(points, big.NewInt(x))
+		if got.Cmp(want) != 0 {
+			t.Errorf("x=%d: got %s, want %s", x, got.RatString(), want.RatString())
+		}
+	}
+}
+
+// BenchmarkLagrangeAtZero compares lagrangeFracAtZero, which sums the terms
+// as one big.Int fraction, with lagrangeInterpolateAt, which sums big.Rat
+// terms.
+func BenchmarkLagrangeAtZero(b *testing.B) {
+	for _, k := range []int{10, 50, 100} {
+		points, _ := pointsOnPolynomial(k, 1)
+		b.Run(fmt.Sprintf("k=%d/int", k), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lagrangeFracAtZero(points)
+			}
+		})
+		b.Run(fmt.Sprintf("k=%d/rat", k), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lagrangeInterpolateAt(points, new(big.Int))
+			}
+		})
+	}
+}
+
+func TestLagrangeFracAtZeroMatchesRat(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for k := 1; k <= 9; k++ {
+		// Random x in [-20, 20] and y, so the secret is usually a fraction.
+		points := make([]Point, 0, k)
+		seen := make(map[int64]bool)
+		for len(points) < k {
+			x := rng.Int63n(41) - 20
+			if seen[x] {
+				continue
+			}
+			seen[x] = true
+			points = append(points, Point{X: big.NewInt(x), Y: big.NewInt(rng.Int63n(2001) - 1000)})
+		}
+
+		num, den := lagrangeFracAtZero(points)
+		got := new(big.Rat).SetFrac(num, den)
+		want := naiveLagrangeAt(points, new(big.Int))
+		if got.Cmp(want) != 0 {
+			t.Errorf("%s: got %s, want %s", PointsString(points), got.RatString(), want.RatString())
+		}
+	}
+}
+
+// naiveLagrangeAt is the direct O(k^2) evaluation of the Lagrange form, kept
+// as a reference for lagrangeInterpolateAt.
+func naiveLagrangeAt(points []Point, x *big.Int) *big.Rat {
+	result := new(big.Rat)
+	xRat := new(big.Rat).SetInt(x)
+	for i := range points {
+		xi := new(big.Rat).SetInt(points[i].X)
+		basis := new(big.Rat).SetInt64(1)
+		for j := range points {
+			if i == j {
+				continue
+			}
+			xj := new(big.Rat).SetInt(points[j].X)
+			basis.Mul(basis, new(big.Rat).Sub(xRat, xj))
+			basis.Quo(basis, new(big.Rat).Sub(xi, xj))
+		}
+		term := new(big.Rat).SetInt(points[i].Y)
+		result.Add(result, term.Mul(term, basis))
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package hashira
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// primalityRounds is the number of Miller-Rabin rounds used to validate a
+// modulus. big.Int.ProbablyPrime is exact for inputs below 2^64.
+const primalityRounds = 20
+
+// RecoverSecretMod returns f(0) for the unique polynomial f of degree
+// len(points)-1 over GF(prime) passing through points. The result is in
+// [0, prime). It returns an error if prime is not prime or if two
+// x-coordinates coincide modulo prime.
+func RecoverSecretMod(points []Point, prime *big.Int) (*big.Int, error) {
+	if err := ValidatePrime(prime); err != nil {
+		return nil, err
+	}
+	if err := validatePoints(points); err != nil {
+		return nil, err
+	}
+
+	secret := new(big.Int)
+	k := len(points)
+
+	for i := 0; i < k; i++ {
+		numerator := big.NewInt(1)
+		denominator := big.NewInt(1)
+
+		for j := 0; j < k; j++ {
+			if i == j {
+				continue
+			}
+			factor := new(big.Int).Neg(points[j].X)
+			numerator.Mul(numerator, factor).Mod(numerator, prime)
+			diff := new(big.Int).Sub(points[i].X, points[j].X)
+			denominator.Mul(denominator, diff).Mod(denominator, prime)
+		}
+
+		inverse := new(big.Int).ModInverse(denominator, prime)
+		if inverse == nil {
+			return nil, fmt.Errorf("x-coordinate %s is not distinct modulo %s", points[i].X, prime)
+		}
+
+		term := new(big.Int).Mul(points[i].Y, numerator)
+		term.Mul(term, inverse)
+		secret.Add(secret, term).Mod(secret, prime)
+	}
+
+	return secret, nil
+}
+
+// ValidatePrime checks that prime is usable as the modulus of GF(prime): it
+// must be non-nil and pass the Miller-Rabin test that every function here
+// taking a prime applies, so callers can reject a modulus up front with the
+// same rule and error text.
+func ValidatePrime(prime *big.Int) error {
+	if prime == nil {
+		return errors.New("modulus is required")
+	}
+	if prime.Cmp(big.NewInt(2)) < 0 || !prime.ProbablyPrime(primalityRounds) {
+		return fmt.Errorf("modulus %s is not prime", prime)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package hashira
+
+import "math/big"
+
+// RecoverCoefficientsNewton returns the same coefficients as
+// RecoverCoefficients, computed with Newton's divided differences instead of
+// Lagrange basis polynomials.
+func RecoverCoefficientsNewton(points []Point) ([]*big.Int, error) {
+	if err := validatePoints(points); err != nil {
+		return nil, err
+	}
+
+	xRats, newton := dividedDifferences(points)
+
+	// Expand f(x) = c_0 + (x - x_0)(c_1 + (x - x_1)(c_2 + ...)) from the
+	// innermost term outwards.
+	k := len(points)
+	coeffs := []*big.Rat{newton[k-1]}
+	for i := k - 2; i >= 0; i-- {
+		coeffs = mulLinear(coeffs, xRats[i])
+		coeffs[0].Add(coeffs[0], newton[i])
+	}
+
+	return integerCoefficients(coeffs)
+}
+
+// InferDegree returns the smallest degree of a polynomial passing through
+// every one of points. The points determine a polynomial of degree at most
+// len(points)-1; a lower result means some of them are redundant, for
+// example because the declared threshold k is larger than necessary. The
+// zero polynomial is reported as degree 0.
+func InferDegree(points []Point) (int, error) {
+	if err := validatePoints(points); err != nil {
+		return 0, err
+	}
+
+	// The Newton coefficient f[x_0, ..., x_j] is the leading coefficient of
+	// the interpolant through the first j+1 points, so the degree is the
+	// index of the last nonzero one.
+	_, newton := dividedDifferences(points)
+	for d := len(newton) - 1; d > 0; d-- {
+		if newton[d].Sign() != 0 {
+			return d, nil
+		}
+	}
+	return 0, nil
+}
+
+// dividedDifferences returns the x-coordinates of points as rationals and the
+// Newton coefficients f[x_0], f[x_0, x_1], ..., f[x_0, ..., x_{k-1}].
+func dividedDifferences(points []Point) ([]*big.Rat, []*big.Rat) {
+	k := len(points)
+	xRats := make([]*big.Rat, k)
+	table := make([]*big.Rat, k)
+	for i := 0; i < k; i++ {
+		xRats[i] = new(big.Rat).SetInt(points[i].X)
+		table[i] = new(big.Rat).SetInt(points[i].Y)
+	}
+
+	// After pass j, table[i] holds f[x_{i-j}, ..., x_i] for i >= j.
+	for j := 1; j < k; j++ {
+		for i := k - 1; i >= j; i-- {
+			diff := new(big.Rat).Sub(xRats[i], xRats[i-j])
+			table[i].Sub(table[i], table[i-1])
+			table[i].Quo(table[i], diff)
+		}
+	}
+
+	return xRats, table
+}
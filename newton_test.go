@@ -0,0 +1,61 @@
+package hashira
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRecoverCoefficientsNewtonMatchesLagrange(t *testing.T) {
+	tests := []struct {
+		name   string
+		coeffs []int64 // constant term first
+		xs     []int64
+	}{
+		{"constant", []int64{42}, []int64{5}},
+		{"line", []int64{1, 1}, []int64{1, 2}},
+		{"quadratic", []int64{3, 0, 1}, []int64{1, 2, 3}},
+		{"cubic negative x", []int64{-7, 2, 0, 5}, []int64{-3, -1, 2, 4}},
+		{"quintic sparse x", []int64{11, -4, 9, 0, -2, 1}, []int64{1, 10, 100, 1000, 10000, 100000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := make([]Point, len(tt.xs))
+			for i, x := range tt.xs {
+				points[i] = Point{X: big.NewInt(x), Y: evalInt64(tt.coeffs, x)}
+			}
+
+			lagrange, err := RecoverCoefficients(points)
+			if err != nil {
+				t.Fatalf("RecoverCoefficients: %v", err)
+			}
+			newton, err := RecoverCoefficientsNewton(points)
+			if err != nil {
+				t.Fatalf("RecoverCoefficientsNewton: %v", err)
+			}
+
+			if len(newton) != len(tt.coeffs) || len(lagrange) != len(tt.coeffs) {
+				t.Fatalf("got %d Newton and %d Lagrange coefficients, want %d", len(newton), len(lagrange), len(tt.coeffs))
+			}
+			for d, want := range tt.coeffs {
+				if newton[d].Cmp(lagrange[d]) != 0 {
+					t.Errorf("x^%d: Newton %s, Lagrange %s", d, newton[d], lagrange[d])
+				}
+				if newton[d].Int64() != want {
+					t.Errorf("x^%d: got %s, want %d", d, newton[d], want)
+				}
+			}
+		})
+	}
+}
+
+// evalInt64 evaluates the polynomial with the given coefficients, constant
+// term first, at x.
+func evalInt64(coeffs []int64, x int64) *big.Int {
+	y := new(big.Int)
+	bx := big.NewInt(x)
+	for d := len(coeffs) - 1; d >= 0; d-- {
+		y.Mul(y, bx).Add(y, big.NewInt(coeffs[d]))
+	}
+	return y
+}
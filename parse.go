@@ -0,0 +1,446 @@
+package hashira
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Input is a decoded share file.
+type Input struct {
+	Points []Point // shares in the order they appear in the file
+	K      int     // number of shares needed to recover the secret
+	N      int     // total number of shares, or 0 if the file does not say
+	// Scale is set when Decoder.Decimals is on and some values had a
+	// fractional part. Every Y has then been multiplied by Scale to make it
+	// an integer, and since interpolation is linear in the y-coordinates,
+	// the secret of the original shares is the secret of Points divided by
+	// Scale.
+	Scale *big.Int
+	// Warnings lists the parts of the file that were skipped rather than
+	// treated as errors, in file order.
+	Warnings []Warning
+}
+
+// A Warning describes a top-level key of a share file that was skipped.
+type Warning struct {
+	Key string // the key as written in the input
+	Err error  // why it was skipped, a *DecodeError
+}
+
+// ParseInput decodes a share file into its points, in file order, and the
+// threshold k. Two layouts are accepted and detected from the top-level keys:
+//
+//	{"keys": {"n": 4, "k": 3}, "1": {"base": "10", "value": "4"}, ...}
+//	{"n": 4, "k": 3, "shares": [{"x": "1", "base": "10", "value": "4"}, ...]}
+//
+// x-coordinates are base-10 integers, or hexadecimal, octal or binary with a
+// 0x, 0o or 0b prefix, so "0x0a" and "10" name the same share. In the keyed
+// layout, top-level keys that are not integers are skipped; a Decoder
+// reports them in Input.Warnings. A share whose "base" is missing or empty
+// is read in base 10, or in a Decoder's DefaultBase. Malformed shares are
+// reported as *DecodeError and other problems with the file as *FormatError.
+func ParseInput(data []byte) (points []Point, k int, err error) {
+	in, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		return nil, 0, err
+	}
+	return in.Points, in.K, nil
+}
+
+// A Decoder reads a share file from a stream one entry at a time, without
+// loading the whole file into memory. It accepts the layouts described at
+// ParseInput.
+type Decoder struct {
+	// StopEarly makes Decode return as soon as Limit points have been read,
+	// leaving the rest of the stream unread.
+	StopEarly bool
+	// Limit is the number of points after which StopEarly takes effect. If
+	// it is 0, the threshold k from the input is used once it has been read.
+	Limit int
+	// MaxPoints, if positive, is the largest number of points Decode
+	// accepts. A file with more points is an error, which bounds the memory
+	// and work spent on hostile input.
+	MaxPoints int
+	// Decimals allows values with a fractional part after a '.', decoded
+	// with DecodeDecimal. See Input.Scale.
+	Decimals bool
+	// ValuePath lists the keys leading from each share's object to the
+	// object holding its "base" and "value", for producers that nest them.
+	// With ValuePath {"data"}, a share is written
+	//
+	//	"1": {"data": {"base": "10", "value": "4"}}
+	//
+	// in the keyed layout, or {"x": "1", "data": {...}} in the flat one. If
+	// it is empty, "base" and "value" are read from the share's object
+	// itself.
+	ValuePath []string
+	// DefaultBase, if not 0, is the base of the values of shares that have
+	// no "base". Otherwise such values are read in base 10.
+	DefaultBase int
+
+	dec      *json.Decoder
+	in       Input
+	seenKeys bool
+	// fracs holds the fractional values decoded with Decimals, by index in
+	// in.Points, until Decode scales them.
+	fracs map[int]*big.Rat
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the share file. Errors are reported as for ParseInput.
+func (d *Decoder) Decode() (*Input, error) {
+	if err := d.decode(); err != nil {
+		var de *DecodeError
+		if !errors.As(err, &de) {
+			err = &FormatError{Err: err}
+		}
+		return nil, err
+	}
+	d.scale()
+	return &d.in, nil
+}
+
+// scale makes every decoded y-coordinate an integer by multiplying them all
+// by the least common multiple of the denominators of the fractional ones,
+// and records that multiple in Input.Scale unless it is 1.
+func (d *Decoder) scale() {
+	if len(d.fracs) == 0 {
+		return
+	}
+
+	lcm := big.NewInt(1)
+	for _, r := range d.fracs {
+		gcd := new(big.Int).GCD(nil, nil, lcm, r.Denom())
+		lcm.Mul(lcm, new(big.Int).Quo(r.Denom(), gcd))
+	}
+
+	for i, p := range d.in.Points {
+		if r, ok := d.fracs[i]; ok {
+			p.Y = new(big.Int).Quo(lcm, r.Denom())
+			p.Y.Mul(p.Y, r.Num())
+		} else {
+			p.Y = new(big.Int).Mul(p.Y, lcm)
+		}
+		d.in.Points[i] = p
+	}
+	if lcm.Cmp(big.NewInt(1)) != 0 {
+		d.in.Scale = lcm
+	}
+}
+
+func (d *Decoder) decode() error {
+	if err := expectDelim(d.dec, '{'); err != nil {
+		return err
+	}
+
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return fmt.Errorf("parsing JSON: %w", err)
+		}
+		key := tok.(string)
+
+		switch key {
+		case "keys":
+			if err := d.decodeKeys(); err != nil {
+				return err
+			}
+		case "k":
+			if err := d.dec.Decode(&d.in.K); err != nil {
+				return fmt.Errorf("parsing 'k': %w", err)
+			}
+			d.seenKeys = true
+		case "n":
+			if err := d.dec.Decode(&d.in.N); err != nil {
+				return fmt.Errorf("parsing 'n': %w", err)
+			}
+		case "shares":
+			stopped, err := d.decodeShares()
+			if err != nil || stopped {
+				return err
+			}
+		default:
+			if err := d.decodeKeyedPoint(key); err != nil {
+				return err
+			}
+		}
+
+		if d.done() {
+			return nil
+		}
+	}
+
+	if err := expectDelim(d.dec, '}'); err != nil {
+		return err
+	}
+	if _, err := d.dec.Token(); err != io.EOF {
+		return errors.New("parsing JSON: unexpected data after top-level object")
+	}
+	if !d.seenKeys {
+		return errors.New("missing required 'keys' object")
+	}
+	return nil
+}
+
+// decodeKeys decodes the "keys" object of the keyed layout, which must hold
+// an integer k and may hold an integer n.
+func (d *Decoder) decodeKeys() error {
+	var keys struct {
+		N *int `json:"n"`
+		K *int `json:"k"`
+	}
+	if err := d.dec.Decode(&keys); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr) && typeErr.Field == "":
+			return fmt.Errorf("malformed 'keys' object: must be an object, got %s", typeErr.Value)
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("malformed 'keys' object: '%s' must be an integer, got %s", typeErr.Field, typeErr.Value)
+		}
+		return fmt.Errorf("malformed 'keys' object: %w", err)
+	}
+	if keys.K == nil {
+		return errors.New("malformed 'keys' object: missing 'k'")
+	}
+	d.in.K = *keys.K
+	if keys.N != nil {
+		d.in.N = *keys.N
+	}
+	d.seenKeys = true
+	return nil
+}
+
+// done reports whether decoding can stop early because enough points have
+// been read.
+func (d *Decoder) done() bool {
+	want := d.Limit
+	if want == 0 && d.seenKeys {
+		want = d.in.K
+	}
+	return d.StopEarly && want > 0 && len(d.in.Points) >= want
+}
+
+// decodeShares decodes the "shares" array of the flat layout. It reports
+// stopped=true if it returned before the end of the array because enough
+// points had been read.
+func (d *Decoder) decodeShares() (stopped bool, err error) {
+	if err := expectDelim(d.dec, '['); err != nil {
+		return false, err
+	}
+	for d.dec.More() {
+		var raw json.RawMessage
+		var share struct {
+			X string `json:"x"`
+		}
+		if err := d.dec.Decode(&raw); err != nil {
+			return false, fmt.Errorf("parsing share %d: %w", len(d.in.Points), err)
+		}
+		if err := json.Unmarshal(raw, &share); err != nil {
+			return false, fmt.Errorf("parsing share %d: %w", len(d.in.Points), err)
+		}
+		val, err := d.shareValue(raw)
+		if err != nil {
+			return false, fmt.Errorf("parsing share %d: %w", len(d.in.Points), err)
+		}
+
+		xVal, ok := parseKey(share.X)
+		if !ok {
+			return false, &DecodeError{
+				Key:   share.X,
+				Base:  val.Base,
+				Value: val.Value,
+				Err:   fmt.Errorf("%w: not an integer", ErrInvalidKey),
+			}
+		}
+		if err := d.addShare(xVal, share.X, val.Base, val.Value); err != nil {
+			return false, err
+		}
+
+		if d.done() {
+			return true, nil
+		}
+	}
+	return false, expectDelim(d.dec, ']')
+}
+
+// decodeKeyedPoint decodes the value following key in the keyed layout.
+// Keys that are not x-coordinates are skipped and recorded as warnings.
+func (d *Decoder) decodeKeyedPoint(key string) error {
+	xVal, ok := parseKey(key)
+	if !ok {
+		err := &DecodeError{Key: key, Err: fmt.Errorf("%w: not an integer", ErrInvalidKey)}
+		d.in.Warnings = append(d.in.Warnings, Warning{Key: key, Err: err})
+		var skip json.RawMessage
+		if err := d.dec.Decode(&skip); err != nil {
+			return fmt.Errorf("parsing JSON: %w", err)
+		}
+		return nil
+	}
+
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return fmt.Errorf("parsing JSON: %w", err)
+	}
+	val, err := d.shareValue(raw)
+	if err != nil {
+		return &DecodeError{Key: key, Err: fmt.Errorf("%w: %v", ErrInvalidValue, err)}
+	}
+
+	return d.addShare(xVal, key, val.Base, val.Value)
+}
+
+// encodedValue is the base and value of a share as written in the input.
+type encodedValue struct {
+	Base  string `json:"base"`
+	Value string `json:"value"`
+}
+
+// shareValue decodes the base and value from the object of a share, after
+// following ValuePath.
+func (d *Decoder) shareValue(raw json.RawMessage) (encodedValue, error) {
+	for _, key := range d.ValuePath {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return encodedValue{}, err
+		}
+		next, ok := obj[key]
+		if !ok {
+			return encodedValue{}, fmt.Errorf("no %q object", key)
+		}
+		raw = next
+	}
+
+	var val encodedValue
+	if err := json.Unmarshal(raw, &val); err != nil {
+		return encodedValue{}, err
+	}
+	return val, nil
+}
+
+// addShare decodes the base and value of the share at x, which is written as
+// key in the input, and appends it to the decoded points, enforcing
+// MaxPoints.
+func (d *Decoder) addShare(x *big.Int, key, base, value string) error {
+	if d.MaxPoints > 0 && len(d.in.Points) >= d.MaxPoints {
+		return fmt.Errorf("too many points: more than the maximum of %d", d.MaxPoints)
+	}
+	if base == "" && d.DefaultBase != 0 {
+		base = strconv.Itoa(d.DefaultBase)
+	}
+
+	if !d.Decimals || !strings.Contains(value, ".") {
+		p, err := decodeShare(x, key, base, value)
+		if err != nil {
+			return err
+		}
+		d.in.Points = append(d.in.Points, p)
+		return nil
+	}
+
+	b, err := parseBase(key, base, value)
+	if err != nil {
+		return err
+	}
+	y, err := DecodeDecimal(value, b)
+	if err != nil {
+		var de *DecodeError
+		if errors.As(err, &de) {
+			de.Key = key
+		}
+		return err
+	}
+	if d.fracs == nil {
+		d.fracs = make(map[int]*big.Rat)
+	}
+	d.fracs[len(d.in.Points)] = y
+	d.in.Points = append(d.in.Points, Point{X: x, Y: new(big.Int)})
+	return nil
+}
+
+// parseKey parses an x-coordinate written in base 10, or in base 16, 8 or 2
+// after a 0x, 0o or 0b prefix. A leading zero alone does not select octal.
+func parseKey(key string) (*big.Int, bool) {
+	body, negative := key, false
+	if body != "" && (body[0] == '+' || body[0] == '-') {
+		negative = body[0] == '-'
+		body = body[1:]
+	}
+
+	base := 10
+	if len(body) > 2 && body[0] == '0' {
+		if b, ok := radixPrefixes[body[1]|0x20]; ok {
+			base, body = b, body[2:]
+		}
+	}
+	if body == "" || body[0] == '+' || body[0] == '-' {
+		return nil, false
+	}
+
+	x, ok := new(big.Int).SetString(body, base)
+	if !ok {
+		return nil, false
+	}
+	if negative {
+		x.Neg(x)
+	}
+	return x, true
+}
+
+// decodeShare decodes the base and value of the share at x, which is written
+// as key in the input.
+func decodeShare(x *big.Int, key, base, value string) (Point, error) {
+	b, err := parseBase(key, base, value)
+	if err != nil {
+		return Point{}, err
+	}
+
+	y, err := DecodeValue(value, b)
+	if err != nil {
+		var de *DecodeError
+		if errors.As(err, &de) {
+			de.Key = key
+		}
+		return Point{}, err
+	}
+
+	return Point{X: x, Y: y}, nil
+}
+
+// parseBase parses the base of a share, which must be written in base 10.
+func parseBase(key, base, value string) (int, error) {
+	if base == "" {
+		return 10, nil
+	}
+	b, err := strconv.Atoi(base)
+	if err != nil {
+		return 0, &DecodeError{
+			Key:   key,
+			Base:  base,
+			Value: value,
+			Err:   fmt.Errorf("%w %q: not an integer", ErrInvalidBase, base),
+		}
+	}
+	return b, nil
+}
+
+// expectDelim reads the next token from dec and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("parsing JSON: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("parsing JSON: expected %q, found %v", delim, tok)
+	}
+	return nil
+}
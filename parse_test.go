@@ -0,0 +1,148 @@
+package hashira
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// FuzzParseInput checks that ParseInput returns an error rather than
+// panicking on malformed input, and that any points it does return can be
+// interpolated without panicking.
+func FuzzParseInput(f *testing.F) {
+	if data, err := os.ReadFile("data.json"); err == nil {
+		f.Add(data)
+	}
+	f.Add([]byte(`{"keys":{"n":4,"k":3},"1":{"base":"10","value":"4"},"2":{"base":"2","value":"111"},"3":{"base":"10","value":"12"},"6":{"base":"4","value":"213"}}`))
+	f.Add([]byte(`{"n":2,"k":2,"shares":[{"x":"0x1","base":"62","value":"zZ"},{"x":"-2","base":"16","value":"-0xff"}]}`))
+	f.Add([]byte(`{"keys":{"n":2,"k":2},"1":{"base":"10","value":"1"},"01":{"base":"10","value":"2"}}`))
+	f.Add([]byte(`{"keys":{"k":0},"x":{}} trailing`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		points, _, err := ParseInput(data)
+		if err != nil {
+			return
+		}
+		for i, p := range points {
+			if p.X == nil || p.Y == nil {
+				t.Fatalf("point %d has a nil coordinate: %+v", i, p)
+			}
+		}
+		if len(points) <= 8 {
+			RecoverSecret(points)
+		}
+	})
+}
+
+func TestParseInputMissingBase(t *testing.T) {
+	for _, input := range []string{
+		`{"keys":{"n":3,"k":3},"1":{"value":"4"},"2":{"base":"","value":"7"},"3":{"base":"16","value":"c"}}`,
+		`{"n":3,"k":3,"shares":[{"x":"1","value":"4"},{"x":"2","base":"","value":"7"},{"x":"3","base":"16","value":"c"}]}`,
+	} {
+		points, _, err := ParseInput([]byte(input))
+		if err != nil {
+			t.Fatalf("ParseInput(%s): %v", input, err)
+		}
+		if got, want := PointsString(points), "[(1, 4), (2, 7), (3, 12)]"; got != want {
+			t.Errorf("ParseInput(%s) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestDecoderWarnings(t *testing.T) {
+	input := `{"keys":{"n":1,"k":1},"comment":"hand-edited","1":{"base":"10","value":"4"},"two":{"base":"10","value":"7"}}`
+	in, err := NewDecoder(strings.NewReader(input)).Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(in.Points) != 1 {
+		t.Errorf("decoded %d points, want 1", len(in.Points))
+	}
+	if len(in.Warnings) != 2 || in.Warnings[0].Key != "comment" || in.Warnings[1].Key != "two" {
+		t.Fatalf("Warnings = %+v, want the keys comment and two", in.Warnings)
+	}
+	for _, w := range in.Warnings {
+		if !errors.Is(w.Err, ErrInvalidKey) {
+			t.Errorf("warning for %q: %v does not wrap ErrInvalidKey", w.Key, w.Err)
+		}
+	}
+}
+
+func TestDecoderValuePath(t *testing.T) {
+	for _, input := range []string{
+		`{"keys":{"n":2,"k":2},"1":{"data":{"base":"10","value":"4"}},"2":{"data":{"base":"2","value":"111"}}}`,
+		`{"n":2,"k":2,"shares":[{"x":"1","data":{"base":"10","value":"4"}},{"x":"2","data":{"base":"2","value":"111"}}]}`,
+	} {
+		dec := NewDecoder(strings.NewReader(input))
+		dec.ValuePath = []string{"data"}
+		in, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode(%s): %v", input, err)
+		}
+		if got, want := PointsString(in.Points), "[(1, 4), (2, 7)]"; got != want {
+			t.Errorf("Decode(%s) = %s, want %s", input, got, want)
+		}
+	}
+
+	dec := NewDecoder(strings.NewReader(`{"keys":{"n":1,"k":1},"1":{"base":"10","value":"4"}}`))
+	dec.ValuePath = []string{"data"}
+	if _, err := dec.Decode(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Decode without the nested object = %v, want ErrInvalidValue", err)
+	}
+}
+
+// TestParseInputDeterministic checks that points come back in file order on
+// every run, including skipped keys and x-coordinates written twice, rather
+// than in an order that depends on map iteration.
+func TestParseInputDeterministic(t *testing.T) {
+	input := []byte(`{"keys":{"n":5,"k":3},"9":{"base":"10","value":"1"},"note":"x","0x2":{"base":"10","value":"2"},"2":{"base":"10","value":"3"},"-4":{"base":"10","value":"4"},"0b1":{"base":"10","value":"5"}}`)
+	const want = "[(9, 1), (2, 2), (2, 3), (-4, 4), (1, 5)]"
+	for i := 0; i < 50; i++ {
+		points, _, err := ParseInput(input)
+		if err != nil {
+			t.Fatalf("ParseInput: %v", err)
+		}
+		if got := PointsString(points); got != want {
+			t.Fatalf("run %d: ParseInput = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestDecoderDefaultBase(t *testing.T) {
+	input := `{"keys":{"n":3,"k":3},"1":{"value":"ff"},"2":{"base":"","value":"10"},"3":{"base":"10","value":"10"}}`
+	dec := NewDecoder(strings.NewReader(input))
+	dec.DefaultBase = 16
+	in, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got, want := PointsString(in.Points), "[(1, 255), (2, 16), (3, 10)]"; got != want {
+		t.Errorf("Decode = %s, want %s", got, want)
+	}
+}
+
+func TestDecoderDecimals(t *testing.T) {
+	input := `{"keys":{"k":2},"1":{"base":"10","value":"1.5"},"2":{"base":"10","value":"2.25"},"3":{"base":"10","value":"3"}}`
+	dec := NewDecoder(strings.NewReader(input))
+	dec.Decimals = true
+	in, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if in.Scale == nil || in.Scale.Int64() != 4 {
+		t.Fatalf("Scale = %v, want 4", in.Scale)
+	}
+	want := []string{"(1, 6)", "(2, 9)", "(3, 12)"}
+	for i, p := range in.Points {
+		if p.String() != want[i] {
+			t.Errorf("point %d = %s, want %s", i, p, want[i])
+		}
+	}
+
+	dec = NewDecoder(strings.NewReader(`{"keys":{"k":1},"1":{"base":"10","value":"2.0"}}`))
+	dec.Decimals = true
+	if in, err := dec.Decode(); err != nil || in.Scale != nil || in.Points[0].Y.Int64() != 2 {
+		t.Errorf("Decode of a whole decimal = %+v, %v; want y=2 and no scale", in, err)
+	}
+}
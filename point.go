@@ -0,0 +1,69 @@
+package hashira
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Point is a single share: a point on the secret polynomial.
+type Point struct {
+	// X is the x-coordinate (share index) at which the polynomial was evaluated.
+	// It may be negative.
+	X *big.Int
+	// Y is the value of the polynomial at X.
+	Y *big.Int
+}
+
+// String renders p as "(x, y)" in decimal.
+func (p Point) String() string {
+	return "(" + p.X.String() + ", " + p.Y.String() + ")"
+}
+
+// PointsString renders points as "[(x1, y1), (x2, y2), ...]".
+func PointsString(points []Point) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, p := range points {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(p.String())
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// pointJSON is the JSON form of a Point. Coordinates are decimal strings so
+// that values of any size survive encoders that parse numbers as float64.
+type pointJSON struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+// MarshalJSON encodes p as {"x":"<decimal>","y":"<decimal>"}.
+func (p Point) MarshalJSON() ([]byte, error) {
+	if p.X == nil || p.Y == nil {
+		return nil, fmt.Errorf("marshalling point %s: nil coordinate", p)
+	}
+	return json.Marshal(pointJSON{X: p.X.String(), Y: p.Y.String()})
+}
+
+// UnmarshalJSON decodes a point written by MarshalJSON.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var raw pointJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	x, ok := new(big.Int).SetString(raw.X, 10)
+	if !ok {
+		return fmt.Errorf("invalid point x-coordinate %q", raw.X)
+	}
+	y, ok := new(big.Int).SetString(raw.Y, 10)
+	if !ok {
+		return fmt.Errorf("invalid point y-coordinate %q", raw.Y)
+	}
+	p.X, p.Y = x, y
+	return nil
+}
@@ -0,0 +1,33 @@
+package hashira
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestPointJSONRoundTrip(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-123456789012345678901234567890123456789012345678901234567890", 10)
+	in := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(4)},
+		{X: new(big.Int).Lsh(big.NewInt(1), 200), Y: huge},
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out []Point
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("got %d points, want %d", len(out), len(in))
+	}
+	for i := range in {
+		if out[i].X.Cmp(in[i].X) != 0 || out[i].Y.Cmp(in[i].Y) != 0 {
+			t.Errorf("point %d: got %s, want %s", i, out[i], in[i])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package hashira
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report reconstructs the secret from the first k points and returns a
+// multi-line summary of the result: how many points there are, which were
+// used, the secret, and whether the remaining points lie on the recovered
+// polynomial. It returns an error if the secret cannot be recovered.
+func Report(points []Point, k int) (string, error) {
+	if err := validatePoints(points); err != nil {
+		return "", err
+	}
+	if err := validateThreshold(k, len(points)); err != nil {
+		return "", err
+	}
+
+	subset := points[:k]
+	secret, err := lagrangeInterpolateAtZero(subset)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Points parsed: %d\n", len(points))
+	fmt.Fprintf(&b, "Threshold k:   %d\n", k)
+	fmt.Fprintf(&b, "Points used:   x=%s\n", joinX(subset))
+	fmt.Fprintf(&b, "Secret:        %s\n", secret)
+
+	extra := points[k:]
+	switch off := offPolynomial(subset, extra); {
+	case len(extra) == 0:
+		b.WriteString("Extra points:  none to check\n")
+	case len(off) == 0:
+		fmt.Fprintf(&b, "Extra points:  %d checked, all lie on the polynomial\n", len(extra))
+	default:
+		fmt.Fprintf(&b, "Extra points:  %d of %d do not lie on the polynomial: x=%s\n", len(off), len(extra), joinX(off))
+	}
+	return b.String(), nil
+}
+
+// joinX returns the x-coordinates of points separated by commas.
+func joinX(points []Point) string {
+	xs := make([]string, len(points))
+	for i, p := range points {
+		xs[i] = p.X.String()
+	}
+	return strings.Join(xs, ", ")
+}
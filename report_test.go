@@ -0,0 +1,55 @@
+package hashira
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	// f(x) = x^2 + x + 3.
+	points := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(5)},
+		{X: big.NewInt(2), Y: big.NewInt(9)},
+		{X: big.NewInt(3), Y: big.NewInt(15)},
+		{X: big.NewInt(4), Y: big.NewInt(23)},
+		{X: big.NewInt(5), Y: big.NewInt(30)},
+	}
+	tests := []struct {
+		name   string
+		points []Point
+		want   string
+	}{
+		{"no extra points", points[:3], "Points parsed: 3\n" +
+			"Threshold k:   3\n" +
+			"Points used:   x=1, 2, 3\n" +
+			"Secret:        3\n" +
+			"Extra points:  none to check\n"},
+		{"consistent", points[:4], "Points parsed: 4\n" +
+			"Threshold k:   3\n" +
+			"Points used:   x=1, 2, 3\n" +
+			"Secret:        3\n" +
+			"Extra points:  1 checked, all lie on the polynomial\n"},
+		{"outlier", points, "Points parsed: 5\n" +
+			"Threshold k:   3\n" +
+			"Points used:   x=1, 2, 3\n" +
+			"Secret:        3\n" +
+			"Extra points:  1 of 2 do not lie on the polynomial: x=5\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Report(tt.points, 3)
+			if err != nil {
+				t.Fatalf("Report: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Report:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	var insufficient *InsufficientPointsError
+	if got, err := Report(points[:2], 3); got != "" || !errors.As(err, &insufficient) {
+		t.Errorf("Report with too few points = %q, %v; want \"\" and an *InsufficientPointsError", got, err)
+	}
+}
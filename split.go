@@ -0,0 +1,65 @@
+package hashira
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// SplitSecret splits secret into n shares over GF(prime), any k of which
+// are enough to recover it with RecoverSecretMod. The shares are the values
+// of a random polynomial of degree k-1 with the secret as its constant term,
+// evaluated at x = 1..n. The secret must lie in [0, prime) and n must be
+// smaller than prime. The coefficients come from crypto/rand.
+func SplitSecret(secret *big.Int, n, k int, prime *big.Int) ([]Point, error) {
+	return SplitSecretRand(rand.Reader, secret, n, k, prime)
+}
+
+// SplitSecretRand is like SplitSecret but draws the random coefficients
+// from random. Passing a seeded math/rand source makes the shares
+// reproducible, which is useful in tests, but such shares are not secure:
+// anyone who knows or guesses the seed can recompute the polynomial. Use
+// SplitSecret for real secrets.
+func SplitSecretRand(random io.Reader, secret *big.Int, n, k int, prime *big.Int) ([]Point, error) {
+	if err := ValidatePrime(prime); err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Sign() < 0 || secret.Cmp(prime) >= 0 {
+		return nil, fmt.Errorf("secret must be in the range [0, %s)", prime)
+	}
+	if k < 1 || k > n {
+		return nil, fmt.Errorf("invalid threshold: need 1 <= k <= n, got k=%d n=%d", k, n)
+	}
+	if big.NewInt(int64(n)).Cmp(prime) >= 0 {
+		return nil, fmt.Errorf("too many shares: n=%d must be smaller than the modulus %s", n, prime)
+	}
+
+	coeffs := make([]*big.Int, k)
+	coeffs[0] = new(big.Int).Set(secret)
+	for d := 1; d < k; d++ {
+		c, err := rand.Int(random, prime)
+		if err != nil {
+			return nil, fmt.Errorf("generating coefficient: %w", err)
+		}
+		coeffs[d] = c
+	}
+
+	shares := make([]Point, n)
+	for i := 0; i < n; i++ {
+		x := big.NewInt(int64(i + 1))
+		shares[i] = Point{X: x, Y: evalPolyMod(coeffs, x, prime)}
+	}
+
+	return shares, nil
+}
+
+// evalPolyMod evaluates the polynomial with the given coefficients, ordered
+// from the constant term up, at x modulo prime using Horner's method.
+func evalPolyMod(coeffs []*big.Int, x, prime *big.Int) *big.Int {
+	y := new(big.Int)
+	for d := len(coeffs) - 1; d >= 0; d-- {
+		y.Mul(y, x).Add(y, coeffs[d]).Mod(y, prime)
+	}
+	return y
+}
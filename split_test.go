@@ -0,0 +1,35 @@
+package hashira
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestSplitSecretRandIsReproducible(t *testing.T) {
+	secret := big.NewInt(123456789)
+	prime := big.NewInt(2147483647)
+
+	split := func() []Point {
+		shares, err := SplitSecretRand(rand.New(rand.NewSource(42)), secret, 5, 3, prime)
+		if err != nil {
+			t.Fatalf("SplitSecretRand: %v", err)
+		}
+		return shares
+	}
+
+	a, b := split(), split()
+	for i := range a {
+		if a[i].X.Cmp(b[i].X) != 0 || a[i].Y.Cmp(b[i].Y) != 0 {
+			t.Fatalf("share %d differs between runs with the same seed: %s and %s", i, a[i], b[i])
+		}
+	}
+
+	got, err := RecoverSecretMod([]Point{a[4], a[0], a[2]}, prime)
+	if err != nil {
+		t.Fatalf("RecoverSecretMod: %v", err)
+	}
+	if got.Cmp(secret) != 0 {
+		t.Errorf("RecoverSecretMod = %s, want %s", got, secret)
+	}
+}
@@ -0,0 +1,83 @@
+package hashira
+
+import (
+	"errors"
+	"fmt"
+)
+
+// InsufficientPointsError reports that there are fewer points than the
+// threshold k needed to recover the secret.
+type InsufficientPointsError struct {
+	K      int // the threshold
+	Points int // the number of points available
+}
+
+func (e *InsufficientPointsError) Error() string {
+	return fmt.Sprintf("not enough points (%d) to meet requirement k=%d", e.Points, e.K)
+}
+
+// ValidateShareSet reports whether points can be used to recover a secret
+// shared with threshold k: k must be at least 1, there must be at least
+// MinPointsRequired(k) points, none may have a nil coordinate and no two may
+// share an x-coordinate. Too few points are reported as
+// *InsufficientPointsError. It does not check that the points agree with
+// each other; see ValidateShareSetConsistent.
+func ValidateShareSet(points []Point, k int) error {
+	if err := validateThreshold(k, len(points)); err != nil {
+		return err
+	}
+	return validatePoints(points)
+}
+
+// ValidateShareSetConsistent is ValidateShareSet that also checks that every
+// point lies on the polynomial of degree k-1 through the first k, returning
+// a *MismatchError listing those that do not.
+func ValidateShareSetConsistent(points []Point, k int) error {
+	if err := ValidateShareSet(points, k); err != nil {
+		return err
+	}
+	if off := offPolynomial(points[:k], points[k:]); len(off) > 0 {
+		return &MismatchError{Points: off}
+	}
+	return nil
+}
+
+// MinPointsRequired returns the number of points needed to recover a secret
+// shared with threshold k. It is k itself: a polynomial of degree k-1 is
+// determined by k of its points and no fewer.
+func MinPointsRequired(k int) int {
+	return k
+}
+
+// validateThreshold reports an error unless 1 <= k <= n, returning an
+// *InsufficientPointsError if k is larger than the n points available.
+func validateThreshold(k, n int) error {
+	if k < 1 {
+		return fmt.Errorf("invalid k=%d: must be at least 1", k)
+	}
+	if n < MinPointsRequired(k) {
+		return &InsufficientPointsError{K: k, Points: n}
+	}
+	return nil
+}
+
+// validatePoints reports an error if points cannot be interpolated: if there
+// are none, if a point has a nil coordinate, or if two points share an
+// x-coordinate and a Lagrange denominator would be zero.
+func validatePoints(points []Point) error {
+	if len(points) == 0 {
+		return errors.New("no points given")
+	}
+	seen := make(map[string]struct{}, len(points))
+	for i, p := range points {
+		if p.X == nil || p.Y == nil {
+			return fmt.Errorf("point %d has a nil coordinate", i)
+		}
+		key := p.X.String()
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("duplicate x-coordinate: %s", key)
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
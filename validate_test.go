@@ -0,0 +1,45 @@
+package hashira
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestValidateShareSet(t *testing.T) {
+	// f(x) = 3 + x + x^2, with the last point off the polynomial.
+	points := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(5)},
+		{X: big.NewInt(2), Y: big.NewInt(9)},
+		{X: big.NewInt(3), Y: big.NewInt(15)},
+		{X: big.NewInt(4), Y: big.NewInt(24)},
+	}
+
+	if err := ValidateShareSet(points, 3); err != nil {
+		t.Errorf("ValidateShareSet: %v", err)
+	}
+	if err := ValidateShareSetConsistent(points[:3], 3); err != nil {
+		t.Errorf("ValidateShareSetConsistent on consistent points: %v", err)
+	}
+
+	var mismatch *MismatchError
+	if err := ValidateShareSetConsistent(points, 3); !errors.As(err, &mismatch) || len(mismatch.Points) != 1 || mismatch.Points[0].X.Int64() != 4 {
+		t.Errorf("ValidateShareSetConsistent = %v, want a *MismatchError for x=4", err)
+	}
+
+	var insufficient *InsufficientPointsError
+	if err := ValidateShareSet(points, 5); !errors.As(err, &insufficient) {
+		t.Errorf("ValidateShareSet with k=5 = %v, want *InsufficientPointsError", err)
+	}
+	if err := ValidateShareSet(points, 0); err == nil {
+		t.Error("ValidateShareSet with k=0: got nil error")
+	}
+
+	dup := append([]Point{{X: big.NewInt(2), Y: big.NewInt(1)}}, points...)
+	if err := ValidateShareSet(dup, 3); err == nil {
+		t.Error("ValidateShareSet with a duplicate x: got nil error")
+	}
+	if err := ValidateShareSet([]Point{{X: big.NewInt(1)}}, 1); err == nil {
+		t.Error("ValidateShareSet with a nil y: got nil error")
+	}
+}
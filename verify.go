@@ -0,0 +1,58 @@
+package hashira
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MismatchError is returned by VerifySecret when some points do not lie on
+// the polynomial reconstructed from the first k points.
+type MismatchError struct {
+	Points []Point
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%d points do not lie on the reconstructed polynomial: x=%s", len(e.Points), joinX(e.Points))
+}
+
+// VerifySecret reports whether secret is the constant term of the polynomial
+// through the first k points and every remaining point lies on that
+// polynomial. If the secret matches but some remaining points do not, it
+// returns false and a *MismatchError listing them.
+func VerifySecret(points []Point, k int, secret *big.Int) (bool, error) {
+	if err := validatePoints(points); err != nil {
+		return false, err
+	}
+	if err := validateThreshold(k, len(points)); err != nil {
+		return false, err
+	}
+
+	subset := points[:k]
+	got := lagrangeInterpolateAt(subset, big.NewInt(0))
+	if got.Cmp(new(big.Rat).SetInt(secret)) != 0 {
+		return false, nil
+	}
+
+	if mismatched := offPolynomial(subset, points[k:]); len(mismatched) > 0 {
+		return false, &MismatchError{Points: mismatched}
+	}
+	return true, nil
+}
+
+// offPolynomial returns the points that do not lie on the polynomial through
+// subset, in the order they appear in points.
+func offPolynomial(subset, points []Point) []Point {
+	var off []Point
+	for _, p := range points {
+		if !onPolynomial(subset, p) {
+			off = append(off, p)
+		}
+	}
+	return off
+}
+
+// onPolynomial reports whether p lies on the polynomial through subset.
+func onPolynomial(subset []Point, p Point) bool {
+	y := lagrangeInterpolateAt(subset, p.X)
+	return y.Cmp(new(big.Rat).SetInt(p.Y)) == 0
+}
@@ -0,0 +1,45 @@
+package hashira
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestVerifySecret(t *testing.T) {
+	// f(x) = x^2 + x + 3.
+	points := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(5)},
+		{X: big.NewInt(2), Y: big.NewInt(9)},
+		{X: big.NewInt(3), Y: big.NewInt(15)},
+		{X: big.NewInt(4), Y: big.NewInt(23)},
+		{X: big.NewInt(5), Y: big.NewInt(33)},
+	}
+
+	if ok, err := VerifySecret(points, 3, big.NewInt(3)); !ok || err != nil {
+		t.Errorf("VerifySecret with the right secret = %v, %v; want true, nil", ok, err)
+	}
+	if ok, err := VerifySecret(points, 3, big.NewInt(4)); ok || err != nil {
+		t.Errorf("VerifySecret with the wrong secret = %v, %v; want false, nil", ok, err)
+	}
+
+	bad := append([]Point(nil), points...)
+	bad[3] = Point{X: big.NewInt(4), Y: big.NewInt(24)}
+	bad[4] = Point{X: big.NewInt(5), Y: big.NewInt(30)}
+	ok, err := VerifySecret(bad, 3, big.NewInt(3))
+	var mismatch *MismatchError
+	if ok || !errors.As(err, &mismatch) {
+		t.Fatalf("VerifySecret with two points off the polynomial = %v, %v; want false and a *MismatchError", ok, err)
+	}
+	if len(mismatch.Points) != 2 || mismatch.Points[0].X.Int64() != 4 || mismatch.Points[1].X.Int64() != 5 {
+		t.Errorf("MismatchError.Points = %s, want the points at x=4 and x=5", PointsString(mismatch.Points))
+	}
+
+	dup := []Point{points[0], points[1], {X: big.NewInt(1), Y: big.NewInt(7)}}
+	if _, err := VerifySecret(dup, 3, big.NewInt(3)); err == nil {
+		t.Error("VerifySecret with a duplicate x-coordinate: got nil error")
+	}
+	if _, err := VerifySecret(points[:2], 3, big.NewInt(3)); err == nil {
+		t.Error("VerifySecret with fewer than k points: got nil error")
+	}
+}